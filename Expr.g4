@@ -1,12 +1,16 @@
 grammar Expr ;
 
-start: boolExpression EOF ;
+// expression is tried first, so `$a`, `f()` and `($a)` are values unless they are used as bool
+start
+        : expression EOF
+        | boolExpression EOF
+        ;
 
 boolExpression
         : '!' boolExpression                                            # Not
         | boolExpression op=(EQ | NEQ )  boolExpression                 # BoolCompare
         | expression op=(GT | LT | GTE | LTE | EQ | NEQ ) expression    # Compare
-        | expression  op=(IN | NOTIN) (stringList | numberList)         # In
+        | expression  op=(IN | NOTIN) (stringList | numberList | expression)  # In
         | boolExpression AND boolExpression                             # And
         | boolExpression OR boolExpression                              # Or
         | BOOLEAN                                                       # Boolean
@@ -17,7 +21,8 @@ boolExpression
         ;
 
 expression
-        : expression op=(MUL | DIV) expression      # MulDiv
+        : <assoc=right> expression op=POW expression    # Pow
+        | expression op=(MUL | DIV | MOD) expression    # MulDiv
         | '-' expression                            # SubExpression
         | expression op=(ADD | SUB) expression      # AddSub
         | STRING                                    # String
@@ -26,6 +31,8 @@ expression
         | VAR                                       # Variable
         | function                                  # ExpressionFunction
         | '(' expression ')'                        # Bracket
+        | listLiteral                               # ListExpression
+        | mapLiteral                                # MapExpression
         ;
 
 number: INT | FLOAT;
@@ -39,15 +46,32 @@ numberList
         : '(' number (',' number)* ')'
         ;
 
+listLiteral
+        : '[' ']'
+        | '[' element (',' element)* ']'
+        ;
+
+mapLiteral
+        : '{' '}'
+        | '{' pair (',' pair)* '}'
+        ;
+
+pair: key=STRING ':' element ;
+
+// expression is tried first, like start
+element
+        : expression
+        | boolExpression
+        ;
+
 function: name=IDENTIFIER '(' fnargs=args? ')';
 
 args: arg (',' arg)*;
 
+// expression is tried first, like start
 arg
-    : VAR
-    | number
-    | BOOLEAN
-    | STRING
+    : expression
+    | boolExpression
     ;
 
 
@@ -67,6 +91,7 @@ IN: 'in' ;
 NOTIN: 'not in' ;
 
 
+POW: '**' ;
 MUL: '*' ;
 MOD: '%' ;
 DIV: '/' ;
@@ -82,7 +107,8 @@ BOOLEAN: TRUE | FALSE ;
 
 STRING: '\'' (ESC|.)*? '\'' ;
 
-VAR: '$' IDENTIFIER ;
+// fields and indexes of nested maps, structs and slices, like $order.items[0].sku
+VAR: '$' IDENTIFIER ('.' IDENTIFIER | '[' DIGIT+ ']')* ;
 
 IDENTIFIER: [a-zA-Z_]+[a-zA-Z0-9_]* ;
 
@@ -0,0 +1,282 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/EchoUtopia/expr/parser"
+	"github.com/EchoUtopia/zerror"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// parseANTLR parses input by the parser which ANTLR generates from Expr.g4,
+// Parse used it before the hand written parser, it's kept in tests to compare both
+func parseANTLR(input string) (tree *Tree, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tree, err = nil, zerror.Internal.Errorf(`panic: %v`, recovered)
+		}
+	}()
+	t, err := parseANTLRTree(input)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{input: input, root: lowerANTLR(t)}, nil
+}
+
+func parseANTLRTree(input string) (antlr.Tree, error) {
+	el := &errorListener{input: input}
+	is := antlr.NewInputStream(input)
+
+	// Create the Lexer
+	lexer := parser.NewExprLexer(is)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(el)
+	stream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	// Create the Parser
+	p := parser.NewExprParser(stream)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(el)
+	tree := p.Start()
+	if el.errs != nil {
+		return tree, el.errs
+	}
+	return tree, nil
+}
+
+// errorListener collects errors of the ANTLR lexer and parser, ANTLR counts runes so offsets are bytes only for ASCII
+type errorListener struct {
+	input string
+	errs  ExprErrors
+	*antlr.DefaultErrorListener
+}
+
+func (el *errorListener) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	category, sp := CategorySyntax, span{}
+	if tok, ok := offendingSymbol.(antlr.Token); ok {
+		sp = span{start: tok.GetStart(), end: tok.GetStop() + 1}
+		if sp.end < sp.start {
+			sp.end = sp.start
+		}
+	} else {
+		// the lexer reports the position of the character it can't match
+		category = CategoryLexical
+		sp.start = len(el.input)
+		if i := offsetOf(el.input, line, column); i < len(el.input) {
+			sp.start = i
+		}
+		sp.end = sp.start + 1
+		if sp.end > len(el.input) {
+			sp.end = len(el.input)
+		}
+	}
+	el.errs = append(el.errs, newExprError(el.input, sp, category, zerror.BadRequest.WithMsg(msg)))
+}
+
+// offsetOf returns the offset of line from 1 and column from 0
+func offsetOf(input string, line, column int) int {
+	i := 0
+	for ; line > 1; line-- {
+		next := strings.IndexByte(input[i:], '\n')
+		if next < 0 {
+			return len(input)
+		}
+		i += next + 1
+	}
+	return i + column
+}
+
+// lowering turns the parse tree into nodes
+type lowering struct {
+	*parser.BaseExprListener
+	nodes []node
+}
+
+func lowerANTLR(tree antlr.Tree) node {
+	l := &lowering{BaseExprListener: &parser.BaseExprListener{}}
+	antlr.ParseTreeWalkerDefault.Walk(l, tree)
+	return l.pop()
+}
+
+func (l *lowering) push(n node) {
+	l.nodes = append(l.nodes, n)
+}
+
+func (l *lowering) pop() node {
+	n := l.nodes[len(l.nodes)-1]
+	l.nodes = l.nodes[:len(l.nodes)-1]
+	return n
+}
+
+// popN pops n nodes in the order they were pushed
+func (l *lowering) popN(n int) []node {
+	nodes := make([]node, n)
+	copy(nodes, l.nodes[len(l.nodes)-n:])
+	l.nodes = l.nodes[:len(l.nodes)-n]
+	return nodes
+}
+
+func spanOf(c antlr.ParserRuleContext) span {
+	return span{start: c.GetStart().GetStart(), end: c.GetStop().GetStop() + 1}
+}
+
+func spanOfToken(t antlr.Token) span {
+	return span{start: t.GetStart(), end: t.GetStop() + 1}
+}
+
+var tokenOperators = map[int]operator{
+	parser.ExprParserAND: operatorAnd,
+	parser.ExprParserOR:  operatorOr,
+	parser.ExprParserEQ:  operatorEQ,
+	parser.ExprParserNEQ: operatorNEQ,
+	parser.ExprParserGT:  operatorGT,
+	parser.ExprParserGTE: operatorGTE,
+	parser.ExprParserLT:  operatorLT,
+	parser.ExprParserLTE: operatorLTE,
+	parser.ExprParserADD: operatorAdd,
+	parser.ExprParserSUB: operatorSub,
+	parser.ExprParserMUL: operatorMul,
+	parser.ExprParserDIV: operatorDiv,
+	parser.ExprParserMOD: operatorMod,
+	parser.ExprParserPOW: operatorPow,
+}
+
+func (l *lowering) binary(c antlr.ParserRuleContext, op operator) {
+	y, x := l.pop(), l.pop()
+	l.push(&binaryNode{span: spanOf(c), op: op, x: x, y: y})
+}
+
+func (l *lowering) ExitNot(c *parser.NotContext) {
+	l.push(&unaryNode{span: spanOf(c), op: operatorNot, x: l.pop()})
+}
+
+func (l *lowering) ExitSubExpression(c *parser.SubExpressionContext) {
+	l.push(&unaryNode{span: spanOf(c), op: operatorNeg, x: l.pop()})
+}
+
+func (l *lowering) ExitBoolCompare(c *parser.BoolCompareContext) {
+	l.binary(c, tokenOperators[c.GetOp().GetTokenType()])
+	l.nodes[len(l.nodes)-1].(*binaryNode).bools = true
+}
+
+func (l *lowering) ExitCompare(c *parser.CompareContext) {
+	l.binary(c, tokenOperators[c.GetOp().GetTokenType()])
+}
+
+func (l *lowering) ExitAnd(c *parser.AndContext) {
+	l.binary(c, operatorAnd)
+}
+
+func (l *lowering) ExitOr(c *parser.OrContext) {
+	l.binary(c, operatorOr)
+}
+
+func (l *lowering) ExitAddSub(c *parser.AddSubContext) {
+	l.binary(c, tokenOperators[c.GetOp().GetTokenType()])
+}
+
+func (l *lowering) ExitPow(c *parser.PowContext) {
+	l.binary(c, operatorPow)
+}
+
+func (l *lowering) ExitMulDiv(c *parser.MulDivContext) {
+	l.binary(c, tokenOperators[c.GetOp().GetTokenType()])
+}
+
+func (l *lowering) ExitIn(c *parser.InContext) {
+	y, x := l.pop(), l.pop()
+	l.push(&inNode{
+		span:     spanOf(c),
+		not:      c.GetOp().GetTokenType() == parser.ExprParserNOTIN,
+		x:        x,
+		y:        y,
+		literals: c.StringList() != nil || c.NumberList() != nil,
+	})
+}
+
+func (l *lowering) ExitListLiteral(c *parser.ListLiteralContext) {
+	l.push(&listNode{span: spanOf(c), elems: l.popN(len(c.AllElement()))})
+}
+
+func (l *lowering) ExitMapLiteral(c *parser.MapLiteralContext) {
+	pairs := c.AllPair()
+	m := &mapNode{span: spanOf(c), keys: make([]string, 0, len(pairs)), values: l.popN(len(pairs))}
+	for _, pair := range pairs {
+		m.keys = append(m.keys, convertText(pair.(*parser.PairContext).GetKey().GetText()))
+	}
+	l.push(m)
+}
+
+func (l *lowering) ExitStringList(c *parser.StringListContext) {
+	list := &listNode{span: spanOf(c)}
+	for _, v := range c.AllSTRING() {
+		list.elems = append(list.elems, &literalNode{
+			span: spanOfToken(v.GetSymbol()),
+			val:  stringValue(convertText(v.GetText())),
+		})
+	}
+	l.push(list)
+}
+
+func (l *lowering) ExitNumberList(c *parser.NumberListContext) {
+	l.push(&listNode{span: spanOf(c), elems: l.popN(len(c.AllNumber()))})
+}
+
+func (l *lowering) ExitNumber(c *parser.NumberContext) {
+	var val value
+	text := c.GetText()
+	if c.FLOAT() != nil {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			panic(err)
+		}
+		val = floatValue(f)
+	} else {
+		i, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			panic(err)
+		}
+		val = intValue(i)
+	}
+	l.push(&literalNode{span: spanOf(c), val: val})
+}
+
+func (l *lowering) ExitString(c *parser.StringContext) {
+	l.push(&literalNode{span: spanOf(c), val: stringValue(convertText(c.GetText()))})
+}
+
+func (l *lowering) ExitBoolean(c *parser.BooleanContext) {
+	l.push(&literalNode{span: spanOf(c), val: boolValue(c.GetText() == `true`)})
+}
+
+func (l *lowering) ExitVariable(c *parser.VariableContext) {
+	v, err := newVariableNode(spanOf(c), c.GetText())
+	if err != nil {
+		panic(err)
+	}
+	l.push(v)
+}
+
+func (l *lowering) ExitBoolVariable(c *parser.BoolVariableContext) {
+	v, err := newVariableNode(spanOf(c), c.GetText())
+	if err != nil {
+		panic(err)
+	}
+	l.push(v)
+}
+
+func (l *lowering) ExitIdentifier(c *parser.IdentifierContext) {
+	l.push(&identifierNode{span: spanOf(c), name: c.GetText()})
+}
+
+func (l *lowering) ExitBoolIdentifier(c *parser.BoolIdentifierContext) {
+	l.push(&identifierNode{span: spanOf(c), name: c.GetText()})
+}
+
+func (l *lowering) ExitFunction(c *parser.FunctionContext) {
+	call := &callNode{span: spanOf(c), name: c.GetName().GetText()}
+	if args := c.GetFnargs(); args != nil {
+		call.args = l.popN(len(args.(*parser.ArgsContext).AllArg()))
+	}
+	l.push(call)
+}
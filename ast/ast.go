@@ -0,0 +1,145 @@
+// Package ast declares the types of expression syntax trees.
+// nodes carry their byte offsets in the source, Format turns them back into canonical expression text
+package ast
+
+// Node is an expression node
+type Node interface {
+	// Pos is the byte offset of the first character of the node
+	Pos() int
+	// End is the byte offset after the last character of the node
+	End() int
+}
+
+// Span is the byte offsets [Start, Stop) of a node in the source.
+// nodes created by optimizations, like folded constants, have the span of the nodes they replace
+type Span struct {
+	Start, Stop int
+}
+
+// Pos implements Node
+func (s Span) Pos() int { return s.Start }
+
+// End implements Node
+func (s Span) End() int { return s.Stop }
+
+// Operator is the operator of Unary and BinaryOp
+type Operator uint8
+
+const (
+	Not Operator = iota + 1
+	Neg
+	And
+	Or
+	EQ
+	NEQ
+	GT
+	GTE
+	LT
+	LTE
+	Add
+	Sub
+	Mul
+	Div
+	Mod
+	Pow
+)
+
+var operatorTexts = [...]string{
+	Not: `!`,
+	Neg: `-`,
+	And: `and`,
+	Or:  `or`,
+	EQ:  `=`,
+	NEQ: `!=`,
+	GT:  `>`,
+	GTE: `>=`,
+	LT:  `<`,
+	LTE: `<=`,
+	Add: `+`,
+	Sub: `-`,
+	Mul: `*`,
+	Div: `/`,
+	Mod: `%`,
+	Pow: `**`,
+}
+
+func (o Operator) String() string {
+	return operatorTexts[o]
+}
+
+// IsCompare reports if o is one of = != > >= < <=
+func (o Operator) IsCompare() bool {
+	return o >= EQ && o <= LTE
+}
+
+// IsMath reports if o is one of + - * / % **
+func (o Operator) IsMath() bool {
+	return o >= Add && o <= Pow
+}
+
+// Literal is a bool, int64, float64 or string
+type Literal struct {
+	Span
+	Value interface{}
+}
+
+// Step is a field or an index of Var, like .address or [0]
+type Step struct {
+	Field   string
+	Index   int
+	IsIndex bool
+}
+
+// Var is a variable, like $order.items[0].sku
+type Var struct {
+	Span
+	Name string
+	Path []Step
+}
+
+// Ident is an identifier, it can only be evaluated once bound by PartialEval
+type Ident struct {
+	Span
+	Name string
+}
+
+// Unary is !X or -X
+type Unary struct {
+	Span
+	Op Operator
+	X  Node
+}
+
+// BinaryOp is a logic, compare or math operation
+type BinaryOp struct {
+	Span
+	Op   Operator
+	X, Y Node
+}
+
+// In is `X in Y` or `X not in Y`, Y is a list or a map
+type In struct {
+	Span
+	Not  bool
+	X, Y Node
+}
+
+// List is a list literal, like [1, $a] and ('a', 'b') of in
+type List struct {
+	Span
+	Elems []Node
+}
+
+// Map is a map literal, like {'a': 1}
+type Map struct {
+	Span
+	Keys   []string
+	Values []Node
+}
+
+// Call is a function call
+type Call struct {
+	Span
+	Name string
+	Args []Node
+}
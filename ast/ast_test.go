@@ -0,0 +1,51 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	a := &Var{Name: `a`, Path: []Step{{Field: `b`}, {Index: 1, IsIndex: true}}}
+	cases := map[string]Node{
+		`$a.b[1] + 1 > 2.0`:     &BinaryOp{Op: GT, X: &BinaryOp{Op: Add, X: a, Y: &Literal{Value: int64(1)}}, Y: &Literal{Value: 2.0}},
+		`(1 + 2) * 3`:           &BinaryOp{Op: Mul, X: &BinaryOp{Op: Add, X: &Literal{Value: int64(1)}, Y: &Literal{Value: int64(2)}}, Y: &Literal{Value: int64(3)}},
+		`2 ** 3 ** 2`:           &BinaryOp{Op: Pow, X: &Literal{Value: int64(2)}, Y: &BinaryOp{Op: Pow, X: &Literal{Value: int64(3)}, Y: &Literal{Value: int64(2)}}},
+		`-(2 ** 2)`:             &Unary{Op: Neg, X: &BinaryOp{Op: Pow, X: &Literal{Value: int64(2)}, Y: &Literal{Value: int64(2)}}},
+		`!($x = 'it\'s')`:       &Unary{Op: Not, X: &BinaryOp{Op: EQ, X: &Var{Name: `x`}, Y: &Literal{Value: `it's`}}},
+		`$x not in ['a', true]`: &In{Not: true, X: &Var{Name: `x`}, Y: &List{Elems: []Node{&Literal{Value: `a`}, &Literal{Value: true}}}},
+		`f(ident, {'k': []})`:   &Call{Name: `f`, Args: []Node{&Ident{Name: `ident`}, &Map{Keys: []string{`k`}, Values: []Node{&List{}}}}},
+	}
+	for expect, n := range cases {
+		require.Equal(t, expect, Format(n))
+	}
+}
+
+func TestInspect(t *testing.T) {
+	n := &BinaryOp{
+		Op: And,
+		X:  &Call{Name: `f`, Args: []Node{&Var{Name: `a`}}},
+		Y:  &In{X: &Var{Name: `b`}, Y: &List{Elems: []Node{&Literal{Value: int64(1)}}}},
+	}
+	var visited []string
+	Inspect(n, func(n Node) bool {
+		switch n := n.(type) {
+		case nil:
+			visited = append(visited, `)`)
+		case *Var:
+			visited = append(visited, n.Name)
+		case *Call:
+			visited = append(visited, n.Name)
+			return false
+		default:
+			visited = append(visited, Format(n))
+		}
+		return true
+	})
+	require.Equal(t, []string{
+		`f($a) and $b in [1]`, `f`,
+		`$b in [1]`, `b`, `)`, `[1]`, `1`, `)`, `)`, `)`,
+		`)`,
+	}, visited)
+}
@@ -0,0 +1,195 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// precedences of nodes when formatted, the higher binds tighter
+const (
+	precOr = iota + 1
+	precAnd
+	precCompare
+	precAdd
+	precNeg
+	precMul
+	precPow
+	precAtom
+)
+
+func precOf(n Node) int {
+	switch n := n.(type) {
+	case *BinaryOp:
+		switch {
+		case n.Op == Or:
+			return precOr
+		case n.Op == And:
+			return precAnd
+		case n.Op.IsCompare():
+			return precCompare
+		case n.Op == Add, n.Op == Sub:
+			return precAdd
+		case n.Op == Pow:
+			return precPow
+		}
+		return precMul
+	case *In:
+		return precCompare
+	case *Unary:
+		if n.Op == Not {
+			return precCompare
+		}
+		return precNeg
+	}
+	return precAtom
+}
+
+// Format formats n as canonical expression text, which parses to the same tree:
+// operators are separated by spaces, brackets are written only when needed,
+// floats always have a decimal point and strings are single quoted
+func Format(n Node) string {
+	var b strings.Builder
+	writeNode(&b, n)
+	return b.String()
+}
+
+// writeOperand writes n in brackets if it binds looser than prec
+func writeOperand(b *strings.Builder, n Node, prec int) {
+	if precOf(n) < prec {
+		b.WriteByte('(')
+		writeNode(b, n)
+		b.WriteByte(')')
+		return
+	}
+	writeNode(b, n)
+}
+
+func writeNode(b *strings.Builder, n Node) {
+	switch n := n.(type) {
+	case *Literal:
+		b.WriteString(FormatLiteral(n.Value))
+	case *Var:
+		b.WriteString(FormatVar(n.Name, n.Path))
+	case *Ident:
+		b.WriteString(n.Name)
+	case *Unary:
+		b.WriteString(n.Op.String())
+		if n.Op == Not {
+			// operands of ! are atoms, ! applies to the whole comparison in `!$a = 1`
+			writeOperand(b, n.X, precAtom)
+			return
+		}
+		var x strings.Builder
+		writeOperand(&x, n.X, precMul)
+		// -2 ** 2 is lexed as (-2) ** 2
+		if s := x.String(); s[0] == '-' || s[0] >= '0' && s[0] <= '9' {
+			b.WriteString(`(` + s + `)`)
+		} else {
+			b.WriteString(s)
+		}
+	case *BinaryOp:
+		prec := precOf(n)
+		switch {
+		case prec == precCompare:
+			// operands of comparisons are math or atoms
+			writeOperand(b, n.X, precAdd)
+		case n.Op == Pow:
+			// ** is right associative
+			writeOperand(b, n.X, prec+1)
+		default:
+			writeOperand(b, n.X, prec)
+		}
+		b.WriteString(` ` + n.Op.String() + ` `)
+		switch {
+		case prec == precCompare:
+			writeOperand(b, n.Y, precAdd)
+		case n.Op == Pow, n.Op == And, n.Op == Or:
+			writeOperand(b, n.Y, prec)
+		default:
+			writeOperand(b, n.Y, prec+1)
+		}
+	case *In:
+		writeOperand(b, n.X, precAdd)
+		if n.Not {
+			b.WriteString(` not in `)
+		} else {
+			b.WriteString(` in `)
+		}
+		writeOperand(b, n.Y, precAdd)
+	case *List:
+		b.WriteByte('[')
+		for i, elem := range n.Elems {
+			if i > 0 {
+				b.WriteString(`, `)
+			}
+			writeNode(b, elem)
+		}
+		b.WriteByte(']')
+	case *Map:
+		b.WriteByte('{')
+		for i, key := range n.Keys {
+			if i > 0 {
+				b.WriteString(`, `)
+			}
+			b.WriteString(Quote(key))
+			b.WriteString(`: `)
+			writeNode(b, n.Values[i])
+		}
+		b.WriteByte('}')
+	case *Call:
+		b.WriteString(n.Name)
+		b.WriteByte('(')
+		for i, arg := range n.Args {
+			if i > 0 {
+				b.WriteString(`, `)
+			}
+			writeNode(b, arg)
+		}
+		b.WriteByte(')')
+	}
+}
+
+// Quote quotes s as a string literal
+func Quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `'` + strings.ReplaceAll(s, `'`, `\'`) + `'`
+}
+
+// FormatLiteral formats the value of Literal, infinite floats and NaN can't be parsed back
+func FormatLiteral(v interface{}) string {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, `.`) {
+			s += `.0`
+		}
+		return s
+	case string:
+		return Quote(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// FormatVar formats a variable, like $order.items[0].sku
+func FormatVar(name string, path []Step) string {
+	var b strings.Builder
+	b.WriteString(`$`)
+	b.WriteString(name)
+	for _, step := range path {
+		if step.IsIndex {
+			b.WriteString(`[` + strconv.Itoa(step.Index) + `]`)
+		} else {
+			b.WriteString(`.` + step.Field)
+		}
+	}
+	return b.String()
+}
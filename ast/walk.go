@@ -0,0 +1,52 @@
+package ast
+
+// Visitor's Visit is called for each node by Walk,
+// children of the node are walked with w if it's not nil, followed by w.Visit(nil)
+type Visitor interface {
+	Visit(n Node) (w Visitor)
+}
+
+// Walk traverses n in depth-first order
+func Walk(v Visitor, n Node) {
+	if v = v.Visit(n); v == nil {
+		return
+	}
+	switch n := n.(type) {
+	case *Unary:
+		Walk(v, n.X)
+	case *BinaryOp:
+		Walk(v, n.X)
+		Walk(v, n.Y)
+	case *In:
+		Walk(v, n.X)
+		Walk(v, n.Y)
+	case *List:
+		for _, elem := range n.Elems {
+			Walk(v, elem)
+		}
+	case *Map:
+		for _, value := range n.Values {
+			Walk(v, value)
+		}
+	case *Call:
+		for _, arg := range n.Args {
+			Walk(v, arg)
+		}
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(n Node) Visitor {
+	if f(n) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses n in depth-first order, children of a node are skipped if f returns false for it,
+// f(nil) is called after the children are traversed
+func Inspect(n Node, f func(Node) bool) {
+	Walk(inspector(f), n)
+}
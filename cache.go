@@ -0,0 +1,178 @@
+package expr
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache caches parsed trees by expression, it must be safe for concurrent use
+type Cache interface {
+	Get(expr string) (*Tree, bool)
+	Set(expr string, tree *Tree)
+}
+
+// CacheStats are counters of a cache
+type CacheStats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+	Len         int
+}
+
+// DefaultCache is used by parsers unless another Cache is set
+var DefaultCache = NewLRUCache(1024, 0)
+
+// LRUCache is a size bounded Cache, the least recently used tree is evicted first
+type LRUCache struct {
+	lru *lru
+}
+
+// NewLRUCache creates a cache holding at most size trees, trees expire after ttl if ttl > 0
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	return &LRUCache{lru: newLRU(size, ttl)}
+}
+
+func (c *LRUCache) Get(expr string) (*Tree, bool) {
+	v, ok := c.lru.get(expr)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Tree), true
+}
+
+func (c *LRUCache) Set(expr string, tree *Tree) {
+	c.lru.set(expr, tree)
+}
+
+func (c *LRUCache) Stats() CacheStats {
+	return c.lru.stats()
+}
+
+type lruEntry struct {
+	key      string
+	value    interface{}
+	expireAt time.Time
+}
+
+type lru struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+	st    CacheStats
+	now   func() time.Time
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+	if size < 1 {
+		size = 1
+	}
+	return &lru{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: map[string]*list.Element{},
+		now:   time.Now,
+	}
+}
+
+func (c *lru) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		c.st.Misses++
+		return nil, false
+	}
+	entry := el.Value.(*lruEntry)
+	if c.ttl > 0 && c.now().After(entry.expireAt) {
+		c.remove(el)
+		c.st.Expirations++
+		c.st.Misses++
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	c.st.Hits++
+	return entry.value, true
+}
+
+func (c *lru) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var expireAt time.Time
+	if c.ttl > 0 {
+		expireAt = c.now().Add(c.ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value, entry.expireAt = value, expireAt
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expireAt: expireAt})
+	for c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+		c.st.Evictions++
+	}
+}
+
+func (c *lru) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = map[string]*list.Element{}
+}
+
+// remove must be called with c.mu locked
+func (c *lru) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
+
+func (c *lru) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := c.st
+	st.Len = c.ll.Len()
+	return st
+}
+
+// parseGroup deduplicates concurrent parsing of the same expression
+type parseGroup struct {
+	mu    sync.Mutex
+	calls map[string]*parseCall
+}
+
+type parseCall struct {
+	wg   sync.WaitGroup
+	tree *Tree
+	err  error
+}
+
+var parses = &parseGroup{calls: map[string]*parseCall{}}
+
+// do calls fn once for concurrent callers with the same input, they all get its result
+func (g *parseGroup) do(input string, fn func(string) (*Tree, error)) (*Tree, error) {
+	g.mu.Lock()
+	if c, ok := g.calls[input]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.tree, c.err
+	}
+	c := &parseCall{}
+	c.wg.Add(1)
+	g.calls[input] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, input)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+	c.tree, c.err = fn(input)
+	return c.tree, c.err
+}
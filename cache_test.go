@@ -0,0 +1,99 @@
+package expr
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCacheEviction(t *testing.T) {
+	cache := NewLRUCache(2, 0)
+	parser := NewParser()
+	parser.SetCache(cache)
+	for _, input := range []string{`1 = 1`, `2 = 2`, `1 = 1`, `3 = 3`} {
+		_, err := parser.ParseWithCache(input)
+		require.Nil(t, err, input)
+	}
+	// `2 = 2` is the least recently used
+	_, ok := cache.Get(`2 = 2`)
+	require.False(t, ok)
+	_, ok = cache.Get(`1 = 1`)
+	require.True(t, ok)
+	require.Equal(t, CacheStats{Hits: 2, Misses: 4, Evictions: 1, Len: 2}, cache.Stats())
+}
+
+func TestLRUCacheTTL(t *testing.T) {
+	cache := NewLRUCache(10, time.Minute)
+	now := time.Now()
+	cache.lru.now = func() time.Time { return now }
+	tree, err := parseSyntax(`1 = 1`)
+	require.Nil(t, err)
+	cache.Set(`1 = 1`, tree)
+	_, ok := cache.Get(`1 = 1`)
+	require.True(t, ok)
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.Get(`1 = 1`)
+	require.False(t, ok)
+	require.Equal(t, CacheStats{Hits: 1, Misses: 1, Expirations: 1}, cache.Stats())
+}
+
+func TestParseGroupDedupes(t *testing.T) {
+	group := &parseGroup{calls: map[string]*parseCall{}}
+	var calls int32
+	release := make(chan struct{})
+	parse := func(input string) (*Tree, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return parseSyntax(input)
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tree, err := group.do(`1 = 1`, parse)
+			require.Nil(t, err)
+			require.NotNil(t, tree)
+		}()
+	}
+	// let all goroutines wait on the first call
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(1), calls)
+	require.Empty(t, group.calls)
+}
+
+func TestDisableCache(t *testing.T) {
+	cache := NewLRUCache(10, 0)
+	env := NewEnv(WithCache(cache))
+	_, err := env.Evaluate(`1 = 1`, nil)
+	require.Nil(t, err)
+	require.Equal(t, 1, cache.Stats().Len)
+
+	parser := env.NewParser()
+	parser.SetCache(nil)
+	_, err = parser.ParseWithCache(`2 = 2`)
+	require.Nil(t, err)
+	require.Equal(t, 1, cache.Stats().Len)
+
+	_, err = NewEnv(WithCache(nil)).Evaluate(`3 = 3`, nil)
+	require.Nil(t, err)
+	// syntax errors are not cached
+	_, err = env.Evaluate(`1 = `, nil)
+	require.NotNil(t, err)
+	require.Equal(t, 1, cache.Stats().Len)
+}
+
+func TestEnvProgramCacheBounded(t *testing.T) {
+	env := NewEnv(WithCache(nil))
+	for i := 0; i < programCacheSize+10; i++ {
+		_, err := env.Compile(fmt.Sprintf(`%d = %d`, i, i))
+		require.Nil(t, err)
+	}
+	require.Equal(t, programCacheSize, env.programs.stats().Len)
+}
@@ -0,0 +1,241 @@
+package expr
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// checker infers types of nodes like the parser infers, errors are collected and checking goes on,
+// a node which fails is only known at runtime so its parents don't fail because of it.
+// types are values: reflectArgVal is only known at runtime, numberArgVal is a number known at runtime
+type checker struct {
+	l     *listenerForParse
+	input string
+	errs  ExprErrors
+}
+
+// text returns the source of n without spaces, like the text of a parse tree
+func (c *checker) text(n node) string {
+	sp := n.pos()
+	tokens, errs := lex(c.input[sp.start:sp.end])
+	if errs != nil {
+		return c.input[sp.start:sp.end]
+	}
+	var b strings.Builder
+	for _, tok := range tokens[:len(tokens)-1] {
+		b.WriteString(tok.text)
+	}
+	return b.String()
+}
+
+// fail records err at sp and returns v
+func (c *checker) fail(v reflect.Value, sp span, category ErrorCategory, err error) reflect.Value {
+	c.errs = append(c.errs, newExprError(c.input, sp, category, err))
+	return v
+}
+
+// check infers the type of n, isBool is true for operands of !, and, or and = comparing bools
+func (c *checker) check(n node, isBool bool) reflect.Value {
+	switch n := n.(type) {
+	case *literalNode:
+		return reflect.ValueOf(n.val.interfaceValue())
+	case *variableNode:
+		return c.checkVar(n, isBool)
+	case *identifierNode:
+		if isBool {
+			return boolRVal
+		}
+		return reflectArgVal
+	case *callNode:
+		return c.checkCall(n, isBool)
+	case *unaryNode:
+		if n.op == operatorNot {
+			c.check(n.x, true)
+			return boolRVal
+		}
+		v := c.check(n.x, false)
+		if v.Type() != reflectArgType && !isNumberArg(v) && v.Kind() != reflect.Int64 && v.Kind() != reflect.Float64 {
+			return c.fail(reflectArgVal, n.span, CategoryType, zerror.BadRequest.Errorf(`invalid expression: %s`, c.text(n)))
+		}
+		if v.Type() == reflectArgType {
+			v = numberArgVal
+		}
+		return v
+	case *binaryNode:
+		return c.checkBinary(n)
+	case *inNode:
+		return c.checkIn(n)
+	case *listNode:
+		for _, elem := range n.elems {
+			c.check(elem, false)
+		}
+		return listRVal
+	case *mapNode:
+		for _, v := range n.values {
+			c.check(v, false)
+		}
+		keys := map[string]bool{}
+		for _, key := range n.keys {
+			if keys[key] {
+				c.fail(mapRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`duplicate key: '%s' in %s`, key, c.text(n)))
+			}
+			keys[key] = true
+		}
+		return mapRVal
+	}
+	return c.fail(reflectArgVal, n.pos(), CategoryType, zerror.Internal.Errorf(`unknown node: %T`, n))
+}
+
+// checkVar infers the type of the variable from the schema, without a schema variables are only known at runtime
+func (c *checker) checkVar(n *variableNode, isBool bool) reflect.Value {
+	v := reflectArgVal
+	if isBool {
+		v = boolRVal
+	}
+	schema := c.l.getSchema()
+	if schema == nil {
+		return v
+	}
+	t, ok := schema[n.name]
+	if !ok {
+		return c.fail(v, span{start: n.start, end: n.start + 1 + len(n.name)}, CategoryVariable,
+			errNotFound(`var`, n.name, suggest(n.name, schema.names())))
+	}
+	t, err := typeAt(t, n.name, n.path)
+	if err != nil {
+		return c.fail(v, n.span, CategoryVariable, err)
+	}
+	tv := typeValue(t)
+	switch {
+	case tv.Type() == reflectArgType:
+		return v
+	case isBool && tv.Kind() != reflect.Bool:
+		return c.fail(v, n.span, CategoryType, zerror.BadRequest.Errorf(`expect var: %s bool, got %s`, pathString(n.name, n.path), tv.Type()))
+	}
+	return tv
+}
+
+func (c *checker) checkCall(n *callNode, isBool bool) reflect.Value {
+	args := make([]reflect.Value, len(n.args))
+	for i, arg := range n.args {
+		args[i] = c.check(arg, false)
+	}
+	name := span{start: n.start, end: n.start + len(n.name)}
+	fn, err := c.l.getFunc(n.name)
+	if err != nil {
+		return c.fail(reflectArgVal, name, CategoryFunction, err)
+	}
+	if err := fn.checkArity(len(args)); err != nil {
+		return c.fail(reflectArgVal, n.span, CategoryFunction, err)
+	}
+	for i, arg := range args {
+		if !argAssignable(arg, fn.in(i)) {
+			c.fail(reflectArgVal, n.args[i].pos(), CategoryType, zerror.BadRequest.Errorf(`func: %s, arg position: %d expect %s, got %s`, n.name, i, fn.in(i), arg.Type()))
+		}
+	}
+	if fn.returnType.Kind() == reflect.Interface {
+		// results of interface{} are checked at runtime, like variables
+		if isBool {
+			return boolRVal
+		}
+		return reflectArgVal
+	}
+	if isBool && fn.returnType.Kind() != reflect.Bool {
+		return c.fail(boolRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`expect func: %s return bool`, n.name))
+	}
+	return reflect.New(fn.returnType).Elem()
+}
+
+func (c *checker) checkBinary(n *binaryNode) reflect.Value {
+	isBool := n.bools || n.op == operatorAnd || n.op == operatorOr
+	lv := c.check(n.x, isBool)
+	rv := c.check(n.y, isBool)
+	switch {
+	case isBool:
+		return boolRVal
+	case n.op.isMath():
+		lok, rok := c.checkMathOperand(n.x, lv), c.checkMathOperand(n.y, rv)
+		if !lok || !rok {
+			return reflectArgVal
+		}
+		// a float operand makes the result float, int only if both are int, otherwise it's known at runtime
+		if rv.Kind() == reflect.Float64 || lv.Kind() == reflect.Float64 {
+			return floatRVal
+		}
+		if lv.Type() != intRVal.Type() || rv.Type() != intRVal.Type() {
+			return numberArgVal
+		}
+		return intRVal
+	}
+	if lok, rok := c.checkCompareOperand(n.x, lv), c.checkCompareOperand(n.y, rv); !lok || !rok {
+		return boolRVal
+	}
+	rk, lk := rv.Kind(), lv.Kind()
+	if rk == reflect.Bool || lk == reflect.Bool {
+		return c.fail(boolRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`can not compare bool: %s`, c.text(n)))
+	}
+	if isCollection(rv) || isCollection(lv) {
+		return c.fail(boolRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`can not compare list or map: %s`, c.text(n)))
+	}
+	if (rk == reflect.String || lk == reflect.String) && rk != lk && rv.Type() != reflectArgType && lv.Type() != reflectArgType {
+		return c.fail(boolRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`can not compare between (%s)%s and (%s)%s`, lk, c.text(n.x), rk, c.text(n.y)))
+	}
+	return boolRVal
+}
+
+// checkMathOperand reports if n, of type v, can be an operand of math operators
+func (c *checker) checkMathOperand(n node, v reflect.Value) bool {
+	k := v.Kind()
+	if k == reflect.Float64 || k == reflect.Int64 || v.Type() == reflectArgType || isNumberArg(v) {
+		return true
+	}
+	c.fail(v, n.pos(), CategoryType, zerror.BadRequest.Errorf(`can not do math operation with type: (%s)%s`, k, c.text(n)))
+	return false
+}
+
+// checkCompareOperand reports if n, of type v, can be an operand of comparisons
+func (c *checker) checkCompareOperand(n node, v reflect.Value) bool {
+	if !isOpaque(v) {
+		return true
+	}
+	c.fail(v, n.pos(), CategoryType, zerror.BadRequest.Errorf(`can not compare type: (%s)%s`, v.Type(), c.text(n)))
+	return false
+}
+
+func (c *checker) checkIn(n *inNode) reflect.Value {
+	lv := c.check(n.x, false)
+	rv := c.check(n.y, false)
+	if isOpaque(lv) {
+		return c.fail(boolRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`invalid expression: %s, left operand type: %s`, c.text(n), lv.Type()))
+	}
+	if !n.literals {
+		return c.checkInCollection(n, lv, rv)
+	}
+	// bracketed lists have elements of the same type, strings or numbers
+	isStringList := n.y.(*listNode).elems[0].(*literalNode).val.kind == kindString
+	lk := lv.Kind()
+	if lk == reflect.Bool || lv.Type() != reflectArgType &&
+		(isStringList && lk != reflect.String || !isStringList && lk == reflect.String) {
+		return c.fail(boolRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`invalid expression: %s, left operand type: %s`, c.text(n), lk))
+	}
+	return boolRVal
+}
+
+// checkInCollection checks `in` with a list or map expression on the right
+func (c *checker) checkInCollection(n *inNode, lv, rv reflect.Value) reflect.Value {
+	if lv.Kind() == reflect.Bool || isCollection(lv) {
+		return c.fail(boolRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`invalid expression: %s, left operand type: %s`, c.text(n), lv.Kind()))
+	}
+	switch {
+	case rv.Type() == reflectArgType, rv.Kind() == reflect.Slice:
+	case rv.Kind() == reflect.Map:
+		if lv.Type() != reflectArgType && lv.Kind() != reflect.String {
+			return c.fail(boolRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`invalid expression: %s, map keys are strings, got: %s`, c.text(n), lv.Kind()))
+		}
+	default:
+		return c.fail(boolRVal, n.span, CategoryType, zerror.BadRequest.Errorf(`invalid expression: %s, expect list or map, got: %s`, c.text(n), rv.Kind()))
+	}
+	return boolRVal
+}
@@ -0,0 +1,485 @@
+package expr
+
+import (
+	"reflect"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// codegen generates bytecode from nodes,
+// registers are allocated like a stack: a node evaluated into dst uses registers above dst as temporaries
+type codegen struct {
+	code    *bytecode
+	getFunc func(name string) (*function, error)
+	funcs   map[*function]int32
+	names   map[string]int32
+	// slots of identical sub-expressions, see share
+	slots map[node]int32
+	// every node records its value by opTrace, see compileTrace
+	tracing bool
+}
+
+func compileTree(tree *Tree, getFunc func(name string) (*function, error)) (*bytecode, node, kind, error) {
+	n := (&optimizer{getFunc: getFunc}).optimize(tree.root)
+	code, k, err := compileNode(n, getFunc)
+	return code, n, k, err
+}
+
+// compileNode returns the bytecode and the static kind of its result
+func compileNode(n node, getFunc func(name string) (*function, error)) (*bytecode, kind, error) {
+	g := &codegen{
+		code:    &bytecode{},
+		getFunc: getFunc,
+		funcs:   map[*function]int32{},
+		names:   map[string]int32{},
+	}
+	g.code.nslots = g.share(n)
+	k, err := g.gen(n, 0, false)
+	if err != nil {
+		return nil, kindAny, err
+	}
+	return g.code, k, nil
+}
+
+// compileNodes compiles each of roots like compileNode, slots are shared among them,
+// so a sub-expression in several roots is evaluated once if their codes are run by one vm, see vm.exec
+func compileNodes(roots []node, getFunc func(name string) (*function, error)) ([]*bytecode, error) {
+	g := &codegen{getFunc: getFunc}
+	nslots := g.share(roots...)
+	codes := make([]*bytecode, len(roots))
+	for i, root := range roots {
+		g.code = &bytecode{nslots: nslots}
+		g.funcs = map[*function]int32{}
+		g.names = map[string]int32{}
+		if _, err := g.gen(root, 0, false); err != nil {
+			return nil, err
+		}
+		codes[i] = g.code
+	}
+	return codes, nil
+}
+
+// compileTrace is like compileNode, but values of nodes are recorded, and nothing is shared so every node is evaluated
+func compileTrace(n node, getFunc func(name string) (*function, error)) (*bytecode, error) {
+	g := &codegen{
+		code:    &bytecode{},
+		getFunc: getFunc,
+		funcs:   map[*function]int32{},
+		names:   map[string]int32{},
+		tracing: true,
+	}
+	if _, err := g.gen(n, 0, false); err != nil {
+		return nil, err
+	}
+	return g.code, nil
+}
+
+func (g *codegen) emit(op opcode, mode uint8, dst, a, b int32) {
+	g.code.instrs = append(g.code.instrs, instr{op: op, mode: mode, dst: dst, a: a, b: b})
+}
+
+func (g *codegen) use(reg int32) {
+	if int(reg) >= g.code.nregs {
+		g.code.nregs = int(reg) + 1
+	}
+}
+
+func (g *codegen) constant(v value) int32 {
+	g.code.consts = append(g.code.consts, v)
+	return int32(len(g.code.consts) - 1)
+}
+
+func (g *codegen) name(name string) int32 {
+	if i, ok := g.names[name]; ok {
+		return i
+	}
+	g.code.names = append(g.code.names, name)
+	i := int32(len(g.code.names) - 1)
+	g.names[name] = i
+	return i
+}
+
+func (g *codegen) function(fn *function) int32 {
+	if i, ok := g.funcs[fn]; ok {
+		return i
+	}
+	g.code.funcs = append(g.code.funcs, fn)
+	i := int32(len(g.code.funcs) - 1)
+	g.funcs[fn] = i
+	return i
+}
+
+func mathKind(x, y kind) kind {
+	if x == kindInt && y == kindInt {
+		return kindInt
+	}
+	if x.isNumber() && y.isNumber() {
+		return kindFloat
+	}
+	return kindAny
+}
+
+var (
+	intMathOps = map[operator]opcode{
+		operatorAdd: opAddInt, operatorSub: opSubInt, operatorMul: opMulInt, operatorDiv: opDivInt,
+		operatorMod: opModInt, operatorPow: opPowInt,
+	}
+	floatMathOps = map[operator]opcode{
+		operatorAdd: opAddFloat, operatorSub: opSubFloat, operatorMul: opMulFloat, operatorDiv: opDivFloat,
+		operatorMod: opModFloat, operatorPow: opPowFloat,
+	}
+)
+
+// share assigns a slot to each sub-expression which occurs more than once in roots, and returns the number of slots,
+// it's evaluated by its first occurrence which runs, later occurrences load the result from the slot.
+// only pure sub-expressions are shared, and variables alone are cheap to load again
+func (g *codegen) share(roots ...node) int {
+	o := &optimizer{getFunc: g.getFunc}
+	var nodes []node
+	keys := map[node]string{}
+	counts := map[string]int{}
+	visit := func(n node) bool {
+		switch n := n.(type) {
+		case *literalNode, *variableNode, *identifierNode:
+			return false
+		case *listNode, *mapNode:
+			if _, ok := constValue(n); ok {
+				return false
+			}
+		}
+		if !o.pure(n) || hasBoolVariable(n) {
+			return true
+		}
+		key := formatNode(n)
+		nodes = append(nodes, n)
+		keys[n] = key
+		counts[key]++
+		// descendants of later occurrences are never evaluated
+		return counts[key] == 1
+	}
+	for _, root := range roots {
+		inspect(root, visit)
+	}
+	slots := map[string]int32{}
+	for _, n := range nodes {
+		key := keys[n]
+		if counts[key] < 2 {
+			continue
+		}
+		slot, ok := slots[key]
+		if !ok {
+			slot = int32(len(slots))
+			slots[key] = slot
+		}
+		if g.slots == nil {
+			g.slots = map[node]int32{}
+		}
+		g.slots[n] = slot
+	}
+	return len(slots)
+}
+
+// hasBoolVariable reports if n has variables simplified from logic operations, they are loaded differently
+func hasBoolVariable(n node) bool {
+	found := false
+	inspect(n, func(n node) bool {
+		if v, ok := n.(*variableNode); ok && v.isBool {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// gen evaluates n into register dst, wantBool requires the runtime value to be bool
+func (g *codegen) gen(n node, dst int32, wantBool bool) (kind, error) {
+	k, err := g.genSlot(n, dst, wantBool)
+	if err == nil && g.tracing {
+		g.trace(n, dst)
+	}
+	return k, err
+}
+
+// trace records register reg as the value of n
+func (g *codegen) trace(n node, reg int32) {
+	g.code.traced = append(g.code.traced, n)
+	g.emit(opTrace, 0, reg, int32(len(g.code.traced)-1), 0)
+}
+
+// traceConst records values of n and its descendants which are constants not evaluated, reg is a free register
+func (g *codegen) traceConst(n node, reg int32) {
+	switch n := n.(type) {
+	case *listNode:
+		for _, elem := range n.elems {
+			g.traceConst(elem, reg)
+		}
+	case *mapNode:
+		for _, v := range n.values {
+			g.traceConst(v, reg)
+		}
+	}
+	v, _ := constValue(n)
+	g.use(reg)
+	g.emit(opConst, 0, reg, g.constant(v), 0)
+	g.trace(n, reg)
+}
+
+// genSlot evaluates shared sub-expressions once, see share
+func (g *codegen) genSlot(n node, dst int32, wantBool bool) (kind, error) {
+	slot, ok := g.slots[n]
+	if !ok {
+		return g.genNode(n, dst, wantBool)
+	}
+	g.use(dst)
+	g.emit(opLoadSlot, 0, dst, slot, 0)
+	at := len(g.code.instrs) - 1
+	k, err := g.genNode(n, dst, wantBool)
+	if err != nil {
+		return kindAny, err
+	}
+	g.emit(opStoreSlot, 0, dst, slot, 0)
+	g.code.instrs[at].b = int32(len(g.code.instrs))
+	return k, nil
+}
+
+func (g *codegen) genNode(n node, dst int32, wantBool bool) (kind, error) {
+	g.use(dst)
+	switch n := n.(type) {
+	case *literalNode:
+		g.emit(opConst, 0, dst, g.constant(n.val), 0)
+		return n.val.kind, nil
+	case *variableNode:
+		if wantBool || n.isBool {
+			g.genVar(n, dst, varBool)
+			return kindBool, nil
+		}
+		g.genVar(n, dst, varValue)
+		return kindAny, nil
+	case *identifierNode:
+		g.emit(opIdent, 0, dst, g.name(n.name), 0)
+		return kindAny, nil
+	case *unaryNode:
+		if n.op == operatorNot {
+			if _, err := g.gen(n.x, dst, true); err != nil {
+				return kindAny, err
+			}
+			g.emit(opNot, 0, dst, dst, 0)
+			return kindBool, nil
+		}
+		k, err := g.gen(n.x, dst, false)
+		if err != nil {
+			return kindAny, err
+		}
+		switch k {
+		case kindInt:
+			g.emit(opNegInt, 0, dst, dst, 0)
+		case kindFloat:
+			g.emit(opNegFloat, 0, dst, dst, 0)
+		default:
+			g.emit(opNeg, 0, dst, dst, 0)
+		}
+		return k, nil
+	case *binaryNode:
+		return g.genBinary(n, dst)
+	case *inNode:
+		return g.genIn(n, dst)
+	case *listNode:
+		if v, ok := constValue(n); ok {
+			g.emit(opConst, 0, dst, g.constant(v), 0)
+			if g.tracing {
+				for _, elem := range n.elems {
+					g.traceConst(elem, dst+1)
+				}
+			}
+			return kindList, nil
+		}
+		for i, elem := range n.elems {
+			if _, err := g.gen(elem, dst+int32(i), false); err != nil {
+				return kindAny, err
+			}
+		}
+		g.emit(opList, 0, dst, dst, int32(len(n.elems)))
+		return kindList, nil
+	case *mapNode:
+		if v, ok := constValue(n); ok {
+			g.emit(opConst, 0, dst, g.constant(v), 0)
+			if g.tracing {
+				for _, v := range n.values {
+					g.traceConst(v, dst+1)
+				}
+			}
+			return kindMap, nil
+		}
+		for i, v := range n.values {
+			if _, err := g.gen(v, dst+int32(i), false); err != nil {
+				return kindAny, err
+			}
+		}
+		g.code.keys = append(g.code.keys, n.keys)
+		g.emit(opMap, 0, dst, dst, int32(len(g.code.keys)-1))
+		return kindMap, nil
+	case *callNode:
+		fn, err := g.getFunc(n.name)
+		if err != nil {
+			return kindAny, err
+		}
+		// the checker reports it too, opCall counts args in a byte
+		if len(n.args) > maxArgs {
+			return kindAny, fn.checkArity(len(n.args))
+		}
+		for i, arg := range n.args {
+			if v, ok := arg.(*variableNode); ok && fn.in(i).Kind() == reflect.Interface {
+				g.use(dst + int32(i))
+				g.genVar(v, dst+int32(i), varRaw)
+				if g.tracing {
+					g.trace(v, dst+int32(i))
+				}
+				continue
+			}
+			if _, err := g.gen(arg, dst+int32(i), false); err != nil {
+				return kindAny, err
+			}
+		}
+		g.emit(opCall, uint8(len(n.args)), dst, g.function(fn), dst)
+		return kindOfType(fn.returnType), nil
+	}
+	return kindAny, zerror.Internal.Errorf(`unknown node: %T`, n)
+}
+
+func (g *codegen) genVar(n *variableNode, dst int32, mode uint8) {
+	if len(n.path) > 0 {
+		g.code.paths = append(g.code.paths, n.path)
+		g.emit(opVarPath, mode, dst, g.name(n.name), int32(len(g.code.paths)-1))
+	} else {
+		g.emit(opVar, mode, dst, g.name(n.name), 0)
+	}
+}
+
+// constValue returns the value of literals, and lists and maps of literals
+func constValue(n node) (value, bool) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.val, true
+	case *listNode:
+		list := make([]interface{}, 0, len(n.elems))
+		for _, elem := range n.elems {
+			v, ok := constValue(elem)
+			if !ok {
+				return value{}, false
+			}
+			list = append(list, v.interfaceValue())
+		}
+		return listValue(list), true
+	case *mapNode:
+		m := make(map[string]interface{}, len(n.keys))
+		for i, key := range n.keys {
+			v, ok := constValue(n.values[i])
+			if !ok {
+				return value{}, false
+			}
+			m[key] = v.interfaceValue()
+		}
+		return mapValue(m), true
+	}
+	return value{}, false
+}
+
+// genIn searches constant lists without building them, other collections are evaluated into dst+1
+func (g *codegen) genIn(n *inNode, dst int32) (kind, error) {
+	if _, err := g.gen(n.x, dst, false); err != nil {
+		return kindAny, err
+	}
+	var not uint8
+	if n.not {
+		not = 1
+	}
+	if list, ok := n.y.(*listNode); ok {
+		values := make([]value, 0, len(list.elems))
+		for _, elem := range list.elems {
+			v, ok := constValue(elem)
+			if !ok {
+				break
+			}
+			values = append(values, v)
+		}
+		if len(values) == len(list.elems) {
+			g.code.lists = append(g.code.lists, values)
+			g.emit(opIn, not, dst, dst, int32(len(g.code.lists)-1))
+			if g.tracing {
+				g.traceConst(n.y, dst+1)
+			}
+			return kindBool, nil
+		}
+	}
+	if _, err := g.gen(n.y, dst+1, false); err != nil {
+		return kindAny, err
+	}
+	g.emit(opInCollection, not, dst, dst, dst+1)
+	return kindBool, nil
+}
+
+// genLogic short-circuits: y is evaluated into the same register only when x doesn't decide the result
+func (g *codegen) genLogic(n *binaryNode, dst int32) (kind, error) {
+	if _, err := g.gen(n.x, dst, true); err != nil {
+		return kindAny, err
+	}
+	jump := opJumpFalse
+	if n.op == operatorOr {
+		jump = opJumpTrue
+	}
+	g.emit(jump, 0, 0, dst, 0)
+	at := len(g.code.instrs) - 1
+	if _, err := g.gen(n.y, dst, true); err != nil {
+		return kindAny, err
+	}
+	g.code.instrs[at].b = int32(len(g.code.instrs))
+	return kindBool, nil
+}
+
+func (g *codegen) genBinary(n *binaryNode, dst int32) (kind, error) {
+	if n.op == operatorAnd || n.op == operatorOr {
+		return g.genLogic(n, dst)
+	}
+	kx, err := g.gen(n.x, dst, false)
+	if err != nil {
+		return kindAny, err
+	}
+	ky, err := g.gen(n.y, dst+1, false)
+	if err != nil {
+		return kindAny, err
+	}
+	switch {
+	case n.op.isMath():
+		k := mathKind(kx, ky)
+		switch k {
+		case kindInt:
+			g.emit(intMathOps[n.op], 0, dst, dst, dst+1)
+		case kindFloat:
+			g.toFloat(kx, dst)
+			g.toFloat(ky, dst+1)
+			g.emit(floatMathOps[n.op], 0, dst, dst, dst+1)
+		default:
+			g.emit(opMath, uint8(n.op), dst, dst, dst+1)
+		}
+		return k, nil
+	}
+	switch {
+	case kx == kindInt && ky == kindInt:
+		g.emit(opCmpInt, uint8(n.op), dst, dst, dst+1)
+	case kx.isNumber() && ky.isNumber():
+		g.toFloat(kx, dst)
+		g.toFloat(ky, dst+1)
+		g.emit(opCmpFloat, uint8(n.op), dst, dst, dst+1)
+	case kx == kindString && ky == kindString:
+		g.emit(opCmpString, uint8(n.op), dst, dst, dst+1)
+	default:
+		g.emit(opCmp, uint8(n.op), dst, dst, dst+1)
+	}
+	return kindBool, nil
+}
+
+func (g *codegen) toFloat(k kind, reg int32) {
+	if k == kindInt {
+		g.emit(opToFloat, 0, reg, reg, 0)
+	}
+}
@@ -0,0 +1,164 @@
+package expr
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+var emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uint64
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+// isParamType reports if values of expressions can be converted to parameters of t
+func isParamType(t reflect.Type) bool {
+	k := t.Kind()
+	switch {
+	case k == reflect.Bool, k == reflect.String, isIntKind(k), isUintKind(k), isFloatKind(k):
+		return true
+	case k == reflect.Interface:
+		return t.NumMethod() == 0
+	case k == reflect.Slice:
+		return isParamType(t.Elem())
+	case k == reflect.Map:
+		return t.Key().Kind() == reflect.String && isParamType(t.Elem())
+	}
+	return false
+}
+
+// exprType returns the type of t in expressions, nil if t can't be a value
+func exprType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Interface && t.NumMethod() == 0 {
+		return emptyInterfaceType
+	}
+	if k := kindOfType(t); k != kindAny {
+		return k.reflectType()
+	}
+	return nil
+}
+
+// assignable reports if values of kind k can be converted to t, ints can be converted to floats
+func assignable(k kind, t reflect.Type) bool {
+	tk := kindOfType(t)
+	return t.Kind() == reflect.Interface || tk == k || tk == kindFloat && k == kindInt
+}
+
+// convertTo converts x to t, numbers are checked against overflow, lists and maps are converted element by element
+func convertTo(x interface{}, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Interface {
+		if x == nil {
+			return reflect.Zero(t), nil
+		}
+		return reflect.ValueOf(x), nil
+	}
+	rv := reflect.ValueOf(x)
+	if !rv.IsValid() {
+		return reflect.Value{}, zerror.BadRequest.Errorf(`can not convert nil to %s`, t)
+	}
+	if rv.Type() == t {
+		return rv, nil
+	}
+	sk, tk := rv.Kind(), t.Kind()
+	out := reflect.New(t).Elem()
+	switch {
+	case isIntKind(tk):
+		var i int64
+		switch {
+		case isIntKind(sk):
+			i = rv.Int()
+		case isUintKind(sk):
+			if rv.Uint() > math.MaxInt64 {
+				return reflect.Value{}, errOverflow(x, t)
+			}
+			i = int64(rv.Uint())
+		default:
+			return reflect.Value{}, errConvert(x, t)
+		}
+		if out.OverflowInt(i) {
+			return reflect.Value{}, errOverflow(x, t)
+		}
+		out.SetInt(i)
+	case isUintKind(tk):
+		var u uint64
+		switch {
+		case isIntKind(sk):
+			if rv.Int() < 0 {
+				return reflect.Value{}, errOverflow(x, t)
+			}
+			u = uint64(rv.Int())
+		case isUintKind(sk):
+			u = rv.Uint()
+		default:
+			return reflect.Value{}, errConvert(x, t)
+		}
+		if out.OverflowUint(u) {
+			return reflect.Value{}, errOverflow(x, t)
+		}
+		out.SetUint(u)
+	case isFloatKind(tk):
+		var f float64
+		switch {
+		case isIntKind(sk):
+			f = float64(rv.Int())
+		case isUintKind(sk):
+			f = float64(rv.Uint())
+		case isFloatKind(sk):
+			f = rv.Float()
+		default:
+			return reflect.Value{}, errConvert(x, t)
+		}
+		if out.OverflowFloat(f) {
+			return reflect.Value{}, errOverflow(x, t)
+		}
+		out.SetFloat(f)
+	case tk == reflect.Bool && sk == reflect.Bool, tk == reflect.String && sk == reflect.String:
+		return rv.Convert(t), nil
+	case tk == reflect.Slice && (sk == reflect.Slice || sk == reflect.Array):
+		if rv.Type().AssignableTo(t) {
+			return rv, nil
+		}
+		out = reflect.MakeSlice(t, rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			e, err := convertTo(rv.Index(i).Interface(), t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(i).Set(e)
+		}
+	case tk == reflect.Map && sk == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		if rv.Type().AssignableTo(t) {
+			return rv, nil
+		}
+		out = reflect.MakeMapWithSize(t, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			e, err := convertTo(iter.Value().Interface(), t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.SetMapIndex(iter.Key().Convert(t.Key()), e)
+		}
+	default:
+		return reflect.Value{}, errConvert(x, t)
+	}
+	return out, nil
+}
+
+func errConvert(x interface{}, t reflect.Type) error {
+	return zerror.BadRequest.Errorf(`can not convert (%T)%v to %s`, x, x, t)
+}
+
+func errOverflow(x interface{}, t reflect.Type) error {
+	return zerror.BadRequest.Errorf(`(%T)%v overflows %s`, x, x, t)
+}
@@ -0,0 +1,142 @@
+package expr
+
+import (
+	"strings"
+)
+
+var elasticRanges = map[operator]string{operatorGT: `gt`, operatorGTE: `gte`, operatorLT: `lt`, operatorLTE: `lte`}
+
+// ToElastic translates expr into a query of the Elasticsearch query DSL, like {"range": {"age": {"gt": 18}}}.
+// and, or and ! are bool queries of must, should and must_not, comparisons of variables and literals are term and range,
+// `in` with lists and maps is terms, startsWith is prefix, endsWith and contains of string literals are wildcard.
+// term matches exact values, so text fields are better mapped to their keyword fields by WithFields
+func ToElastic(expr string, opts ...QueryOption) (map[string]interface{}, error) {
+	program, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return program.ToElastic(opts...)
+}
+
+// ToElastic translates the program like the package level ToElastic, constants are folded before translating
+func (p *Program) ToElastic(opts ...QueryOption) (map[string]interface{}, error) {
+	w := &elasticWriter{queryOptions: newQueryOptions(opts)}
+	return w.query(p.root)
+}
+
+type elasticWriter struct {
+	queryOptions
+}
+
+func elasticBool(occur string, queries ...interface{}) map[string]interface{} {
+	b := map[string]interface{}{occur: queries}
+	if occur == `should` {
+		b[`minimum_should_match`] = 1
+	}
+	return map[string]interface{}{`bool`: b}
+}
+
+func (w *elasticWriter) query(n node) (map[string]interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		if n.val.kind == kindBool {
+			if n.val.b {
+				return map[string]interface{}{`match_all`: map[string]interface{}{}}, nil
+			}
+			return map[string]interface{}{`match_none`: map[string]interface{}{}}, nil
+		}
+	case *variableNode:
+		field, err := w.field(`elastic`, n, false)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{`term`: map[string]interface{}{field: true}}, nil
+	case *unaryNode:
+		if n.op == operatorNot {
+			x, err := w.query(n.x)
+			if err != nil {
+				return nil, err
+			}
+			return elasticBool(`must_not`, x), nil
+		}
+	case *binaryNode:
+		return w.binary(n)
+	case *inNode:
+		v, ok := n.x.(*variableNode)
+		if !ok {
+			return nil, errTranslate(`elastic`, n, `in is only supported with variables on the left`)
+		}
+		field, err := w.field(`elastic`, v, false)
+		if err != nil {
+			return nil, err
+		}
+		values, err := inValues(`elastic`, n)
+		if err != nil {
+			return nil, err
+		}
+		terms := map[string]interface{}{`terms`: map[string]interface{}{field: values}}
+		if n.not {
+			return elasticBool(`must_not`, terms), nil
+		}
+		return terms, nil
+	case *callNode:
+		v, s, err := stringMatch(`elastic`, n)
+		if err != nil {
+			return nil, err
+		}
+		field, err := w.field(`elastic`, v, false)
+		if err != nil {
+			return nil, err
+		}
+		if n.name == `startsWith` {
+			return map[string]interface{}{`prefix`: map[string]interface{}{field: s}}, nil
+		}
+		pattern := `*` + escapeWildcard(s)
+		if n.name == `contains` {
+			pattern += `*`
+		}
+		return map[string]interface{}{`wildcard`: map[string]interface{}{field: map[string]interface{}{`value`: pattern}}}, nil
+	}
+	return nil, errTranslate(`elastic`, n, `it's not a condition`)
+}
+
+func (w *elasticWriter) binary(n *binaryNode) (map[string]interface{}, error) {
+	if n.op == operatorAnd || n.op == operatorOr {
+		operands := flatten(n)
+		queries := make([]interface{}, len(operands))
+		for i, x := range operands {
+			q, err := w.query(x)
+			if err != nil {
+				return nil, err
+			}
+			queries[i] = q
+		}
+		if n.op == operatorAnd {
+			return elasticBool(`must`, queries...), nil
+		}
+		return elasticBool(`should`, queries...), nil
+	}
+	if cond, ok := boolCondition(n); ok {
+		return w.query(cond)
+	}
+	v, op, lit, ok := fieldComparison(n)
+	if !ok {
+		return nil, errTranslate(`elastic`, n, `only variables can be compared with literals`)
+	}
+	field, err := w.field(`elastic`, v, false)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case operatorEQ:
+		return map[string]interface{}{`term`: map[string]interface{}{field: lit}}, nil
+	case operatorNEQ:
+		return elasticBool(`must_not`, map[string]interface{}{`term`: map[string]interface{}{field: lit}}), nil
+	}
+	return map[string]interface{}{`range`: map[string]interface{}{field: map[string]interface{}{elasticRanges[op]: lit}}}, nil
+}
+
+// escapeWildcard escapes * and ? of wildcard queries by `\`
+func escapeWildcard(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`).Replace(s)
+}
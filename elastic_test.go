@@ -0,0 +1,30 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToElastic(t *testing.T) {
+	queries := map[string]interface{}{}
+	for _, expr := range queryExprs {
+		q, err := ToElastic(expr)
+		require.Nil(t, err, expr)
+		queries[expr] = q
+	}
+	checkGolden(t, `elastic.json`, queries)
+
+	cases := map[string]string{
+		`$a > $b`:             `elastic: can not translate $a > $b, only variables can be compared with literals`,
+		`$a + 1 > 2`:          `elastic: can not translate $a + 1 > 2, only variables can be compared with literals`,
+		`$a in $tags`:         `elastic: can not translate $a in $tags, in is only supported with lists and maps`,
+		`startsWith($a, $b)`:  `elastic: can not translate startsWith($a, $b), the pattern must be a string literal`,
+		`contains('abc', $a)`: `elastic: can not translate contains('abc', $a), the string must be a variable`,
+	}
+	for input, msg := range cases {
+		_, err := ToElastic(input)
+		require.NotNil(t, err, input)
+		require.Contains(t, err.Error(), msg, input)
+	}
+}
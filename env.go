@@ -0,0 +1,287 @@
+package expr
+
+import (
+	"context"
+	"sync"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// Env is an isolated set of functions and options which expressions are compiled and evaluated against.
+// it's safe for concurrent use
+type Env struct {
+	mu       sync.RWMutex
+	funcs    map[string]*function
+	evalOpts []EvalOption
+	cache    Cache
+	// types of variables, nil if any variable can be used
+	schema Schema
+	// compiled programs, dropped whenever funcs change
+	programs *lru
+}
+
+// the max number of compiled programs kept by an Env
+const programCacheSize = 1024
+
+type envOptions struct {
+	noBuiltins bool
+	evalOpts   []EvalOption
+	cache      Cache
+	schema     Schema
+}
+
+// EnvOption configures NewEnv
+type EnvOption func(*envOptions)
+
+// WithoutBuiltins creates an Env without builtin functions
+func WithoutBuiltins() EnvOption {
+	return func(o *envOptions) {
+		o.noBuiltins = true
+	}
+}
+
+// WithEvalOptions sets default options for every evaluation in the Env, options passed to each evaluation take precedence
+func WithEvalOptions(opts ...EvalOption) EnvOption {
+	return func(o *envOptions) {
+		o.evalOpts = append(o.evalOpts, opts...)
+	}
+}
+
+// WithCache sets the cache of parsed trees used by the Env, nil disables caching.
+// DefaultCache is used if not set
+func WithCache(c Cache) EnvOption {
+	return func(o *envOptions) {
+		o.cache = c
+	}
+}
+
+// WithKnownVars adds names of variables whose types are only known at runtime to the schema of the Env,
+// expressions using variables out of the schema fail to parse, with suggestions of the closest names
+func WithKnownVars(names ...string) EnvOption {
+	return func(o *envOptions) {
+		o.schema = o.schema.with(nil)
+		for _, name := range names {
+			o.schema[name] = nil
+		}
+	}
+}
+
+// WithSchema adds the variables of s to the schema of the Env, expressions are checked against their types when parsed,
+// see SchemaOf and SchemaFromJSON
+func WithSchema(s Schema) EnvOption {
+	return func(o *envOptions) {
+		o.schema = o.schema.with(s)
+	}
+}
+
+// the env used by package level functions
+var defaultEnv = NewEnv()
+
+var builtinFunctions = newBuiltinFunctions()
+
+func newBuiltinFunctions() map[string]*function {
+	funcs := make(map[string]*function, len(builtinFuncs))
+	for k, v := range builtinFuncs {
+		var opts []FuncOption
+		if !impureBuiltins[k] {
+			opts = append(opts, Pure())
+		}
+		fn, err := newFunction(k, v, opts...)
+		if err != nil {
+			panic(err)
+		}
+		funcs[k] = fn
+	}
+	return funcs
+}
+
+// NewEnv creates an Env with builtin functions
+func NewEnv(opts ...EnvOption) *Env {
+	o := &envOptions{cache: DefaultCache}
+	for _, opt := range opts {
+		opt(o)
+	}
+	e := &Env{
+		funcs:    map[string]*function{},
+		evalOpts: o.evalOpts,
+		cache:    o.cache,
+		schema:   o.schema,
+		programs: newLRU(programCacheSize, 0),
+	}
+	if !o.noBuiltins {
+		for k, v := range builtinFunctions {
+			e.funcs[k] = v
+		}
+	}
+	return e
+}
+
+// Clone returns a copy of e, changes to the copy don't affect e
+func (e *Env) Clone() *Env {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	c := &Env{
+		funcs:    make(map[string]*function, len(e.funcs)),
+		evalOpts: append([]EvalOption(nil), e.evalOpts...),
+		cache:    e.cache,
+		schema:   e.schema,
+		programs: newLRU(programCacheSize, 0),
+	}
+	for k, v := range e.funcs {
+		c.funcs[k] = v
+	}
+	return c
+}
+
+// Extend returns a clone of e with funcs registered
+func (e *Env) Extend(funcs map[string]interface{}) (*Env, error) {
+	c := e.Clone()
+	for name, fn := range funcs {
+		if err := c.RegisterFunc(name, fn); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// RegisterFunc registers fn in e, it fails if name already exists
+func (e *Env) RegisterFunc(name string, fn interface{}, opts ...FuncOption) error {
+	f, err := newFunction(name, fn, opts...)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.funcs[name]; ok {
+		return zerror.AlreadyExists.WithMsg(name)
+	}
+	e.setFunc(name, f)
+	return nil
+}
+
+// OverrideFunc registers fn in e, replacing the function with the same name if exists
+func (e *Env) OverrideFunc(name string, fn interface{}, opts ...FuncOption) error {
+	f, err := newFunction(name, fn, opts...)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.setFunc(name, f)
+	return nil
+}
+
+// UnregisterFunc removes the function from e
+func (e *Env) UnregisterFunc(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.funcs[name]; !ok {
+		return zerror.NotFound.Errorf(`func: %s not found`, name)
+	}
+	e.setFunc(name, nil)
+	return nil
+}
+
+// setFunc must be called with e.mu locked, nil fn deletes the function
+func (e *Env) setFunc(name string, fn *function) {
+	if fn == nil {
+		delete(e.funcs, name)
+	} else {
+		e.funcs[name] = fn
+	}
+	e.programs.clear()
+}
+
+func (e *Env) getFunc(name string) (*function, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	fn, ok := e.funcs[name]
+	return fn, ok
+}
+
+func (e *Env) funcNames() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	names := make([]string, 0, len(e.funcs))
+	for name := range e.funcs {
+		names = append(names, name)
+	}
+	return names
+}
+
+// NewParser creates a parser which resolves functions in e
+func (e *Env) NewParser() *listenerForParse {
+	l := NewParser()
+	l.env = e
+	l.cache = e.cache
+	return l
+}
+
+// Compile compiles expr against the functions of e
+func (e *Env) Compile(expr string) (*Program, error) {
+	program, err := e.CompileValue(expr)
+	if err != nil {
+		return nil, err
+	}
+	if err := program.expectBool(); err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+// CompileValue is like Compile, but expr can result in any type
+func (e *Env) CompileValue(expr string) (*Program, error) {
+	if cached, ok := e.programs.get(expr); ok {
+		return cached.(*Program), nil
+	}
+	program, err := e.NewParser().CompileValue(expr)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	// functions may change while compiling, the program is still returned but not cached
+	if e.compiledWithCurrentFuncs(program) {
+		e.programs.set(expr, program)
+	}
+	e.mu.Unlock()
+	return program, nil
+}
+
+// compiledWithCurrentFuncs must be called with e.mu locked,
+// pure functions folded out of code are checked too
+func (e *Env) compiledWithCurrentFuncs(p *Program) bool {
+	for name, fn := range p.resolved {
+		if e.funcs[name] != fn {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate evaluates expr with vars in e
+func (e *Env) Evaluate(expr string, vars map[string]interface{}) (bool, error) {
+	return e.EvaluateContext(context.Background(), expr, vars)
+}
+
+// EvaluateContext is like Evaluate, but stops once ctx is done, see Program.EvalContext
+func (e *Env) EvaluateContext(ctx context.Context, expr string, vars map[string]interface{}, opts ...EvalOption) (bool, error) {
+	program, err := e.Compile(expr)
+	if err != nil {
+		return false, err
+	}
+	return program.EvalContext(ctx, vars, opts...)
+}
+
+// EvaluateValue evaluates expr with vars in e, the result can be any type
+func (e *Env) EvaluateValue(expr string, vars map[string]interface{}) (interface{}, error) {
+	return e.EvaluateValueContext(context.Background(), expr, vars)
+}
+
+// EvaluateValueContext is like EvaluateValue, but stops once ctx is done, see Program.EvalContext
+func (e *Env) EvaluateValueContext(ctx context.Context, expr string, vars map[string]interface{}, opts ...EvalOption) (interface{}, error) {
+	program, err := e.CompileValue(expr)
+	if err != nil {
+		return nil, err
+	}
+	return program.EvalValueContext(ctx, vars, opts...)
+}
@@ -0,0 +1,129 @@
+package expr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvIsolation(t *testing.T) {
+	a, b := NewEnv(), NewEnv()
+	require.Nil(t, a.RegisterFunc(`plugin`, func() bool { return true }))
+	require.Nil(t, b.RegisterFunc(`plugin`, func() int64 { return 1 }))
+
+	result, err := a.Evaluate(`plugin()`, nil)
+	require.Nil(t, err)
+	require.True(t, result)
+	// the tree of `plugin()` is cached, but it's still checked against b's functions
+	_, err = b.Evaluate(`plugin()`, nil)
+	require.NotNil(t, err)
+	result, err = b.Evaluate(`plugin() = 1`, nil)
+	require.Nil(t, err)
+	require.True(t, result)
+
+	_, err = Evaluate(`plugin()`, nil)
+	require.NotNil(t, err)
+	_, err = a.Evaluate(`t_bool()`, nil)
+	require.NotNil(t, err)
+}
+
+func TestEnvBuiltins(t *testing.T) {
+	result, err := NewEnv().Evaluate(`startsWith('abc', 'a')`, nil)
+	require.Nil(t, err)
+	require.True(t, result)
+
+	env := NewEnv(WithoutBuiltins())
+	_, err = env.Evaluate(`startsWith('abc', 'a')`, nil)
+	require.NotNil(t, err)
+	require.Nil(t, env.RegisterFunc(`startsWith`, func(s, prefix string) bool { return false }))
+	result, err = env.Evaluate(`startsWith('abc', 'a')`, nil)
+	require.Nil(t, err)
+	require.False(t, result)
+}
+
+func TestEnvOverrideAndUnregister(t *testing.T) {
+	env := NewEnv()
+	require.NotNil(t, env.RegisterFunc(`contains`, func(s, sub string) bool { return false }))
+
+	program, err := env.Compile(`contains('abc', 'b')`)
+	require.Nil(t, err)
+	require.Nil(t, env.OverrideFunc(`contains`, func(s, sub string) bool { return false }))
+	// programs compiled before keep the functions they were compiled with
+	result, err := program.Eval(nil)
+	require.Nil(t, err)
+	require.True(t, result)
+	result, err = env.Evaluate(`contains('abc', 'b')`, nil)
+	require.Nil(t, err)
+	require.False(t, result)
+	// other envs are not affected
+	result, err = Evaluate(`contains('abc', 'b')`, nil)
+	require.Nil(t, err)
+	require.True(t, result)
+
+	require.Nil(t, env.UnregisterFunc(`contains`))
+	require.NotNil(t, env.UnregisterFunc(`contains`))
+	_, err = env.Compile(`contains('abc', 'b')`)
+	require.NotNil(t, err)
+}
+
+func TestEnvFoldedFuncsChanged(t *testing.T) {
+	env := NewEnv()
+	require.Nil(t, env.RegisterFunc(`double`, func(i int64) int64 { return i * 2 }, Pure()))
+
+	program, err := env.NewParser().Compile(`double(2) = 4`)
+	require.Nil(t, err)
+	require.Empty(t, program.code.funcs)
+	require.Nil(t, env.OverrideFunc(`double`, func(i int64) int64 { return i * 3 }, Pure()))
+	// the folded function changed, the program must not be cached
+	env.mu.Lock()
+	require.False(t, env.compiledWithCurrentFuncs(program))
+	env.mu.Unlock()
+	result, err := env.Evaluate(`double(2) = 4`, nil)
+	require.Nil(t, err)
+	require.False(t, result)
+}
+
+func TestEnvCloneAndExtend(t *testing.T) {
+	base := NewEnv()
+	require.Nil(t, base.RegisterFunc(`one`, func() int64 { return 1 }))
+
+	clone := base.Clone()
+	require.Nil(t, clone.UnregisterFunc(`one`))
+	_, err := base.Compile(`one() = 1`)
+	require.Nil(t, err)
+
+	extended, err := base.Extend(map[string]interface{}{`two`: func() int64 { return 2 }})
+	require.Nil(t, err)
+	result, err := extended.Evaluate(`one() + two() = 3`, nil)
+	require.Nil(t, err)
+	require.True(t, result)
+	_, err = base.Compile(`two() = 2`)
+	require.NotNil(t, err)
+
+	_, err = base.Extend(map[string]interface{}{`one`: func() int64 { return 1 }})
+	require.NotNil(t, err)
+}
+
+func TestEnvEvalOptions(t *testing.T) {
+	env := NewEnv(WithEvalOptions(WithCostBudget(3)))
+	vars := map[string]interface{}{`a`: 1}
+	_, err := env.Evaluate(`$a + 2 > 2`, vars)
+	require.True(t, BudgetExceeded.Cause(err), err)
+	program, err := env.Compile(`$a + 2 > 2`)
+	require.Nil(t, err)
+	// options of each evaluation take precedence
+	result, err := program.EvalContext(context.Background(), vars, WithCostBudget(0))
+	require.Nil(t, err)
+	require.True(t, result)
+}
+
+func TestParserFuncsShadowEnv(t *testing.T) {
+	parser := NewEnv().NewParser()
+	require.Nil(t, parser.RegisterFunc(`contains`, func(s, sub string) bool { return false }))
+	program, err := parser.Compile(`contains('abc', 'b')`)
+	require.Nil(t, err)
+	result, err := program.Eval(nil)
+	require.Nil(t, err)
+	require.False(t, result)
+}
@@ -0,0 +1,150 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+var (
+	// Canceled wraps the context error when the context is done before evaluation finishes
+	Canceled = &zerror.Def{
+		Code:        `expr:canceled`,
+		PCode:       zerror.CodeCancelled,
+		Msg:         `evaluation canceled`,
+		Description: `the context is done before evaluation finishes`,
+	}
+	// BudgetExceeded is returned when an evaluation costs more than its budget, see WithCostBudget
+	BudgetExceeded = &zerror.Def{
+		Code:        `expr:budget_exceeded`,
+		PCode:       zerror.CodeResourceExhausted,
+		Msg:         `cost budget exceeded`,
+		Description: `the expression costs more than the budget`,
+	}
+)
+
+// ErrorCategory classifies errors found in the source of expressions
+type ErrorCategory string
+
+const (
+	// CategoryLexical is for source which doesn't form tokens, like `#`, unterminated strings and numbers out of range
+	CategoryLexical ErrorCategory = `lexical`
+	// CategorySyntax is for tokens which don't follow the grammar
+	CategorySyntax ErrorCategory = `syntax`
+	// CategoryType is for operands and results of wrong types, like `'a' + 1`
+	CategoryType ErrorCategory = `type`
+	// CategoryFunction is for unknown functions and wrong numbers of arguments
+	CategoryFunction ErrorCategory = `function`
+	// CategoryVariable is for unknown variables, they are checked only if known variables are given, see WithKnownVars
+	CategoryVariable ErrorCategory = `variable`
+)
+
+// ExprError is an error at a position of an expression.
+// lines start from 1, columns count bytes from 0 like the positions in messages
+type ExprError struct {
+	Category ErrorCategory
+	Msg      string
+	Input    string
+	// Offset and End are byte offsets of the erroneous source, End is exclusive
+	Offset, End        int
+	Line, Column       int
+	EndLine, EndColumn int
+	// Suggestions are names close to unknown functions and variables
+	Suggestions []string
+	err         error
+}
+
+func newExprError(input string, sp span, category ErrorCategory, err error) *ExprError {
+	e := &ExprError{Category: category, Msg: errorMessage(err), Input: input, Offset: sp.start, End: sp.end, err: err}
+	e.Line, e.Column = lineCol(input, sp.start)
+	e.EndLine, e.EndColumn = lineCol(input, sp.end)
+	var ze *zerror.Error
+	if errors.As(err, &ze) && ze.ZContext != nil {
+		e.Suggestions, _ = ze.Data[dataSuggestions].([]string)
+	}
+	return e
+}
+
+// errorMessage is the message of err without the code of zerror
+func errorMessage(err error) string {
+	var ze *zerror.Error
+	if !errors.As(err, &ze) {
+		return err.Error()
+	}
+	if cause := ze.Unwrap(); cause != nil {
+		return cause.Error()
+	}
+	return strings.TrimPrefix(ze.Error(), ze.Code+`: `)
+}
+
+func (e *ExprError) Error() string {
+	return fmt.Sprintf(`line %d:%d %s`, e.Line, e.Column, e.Msg)
+}
+
+// Unwrap returns the zerror of e, like zerror.BadRequest
+func (e *ExprError) Unwrap() error {
+	return e.err
+}
+
+// Snippet renders the line of the error with carets under the erroneous source
+func (e *ExprError) Snippet() string {
+	start := strings.LastIndexByte(e.Input[:e.Offset], '\n') + 1
+	end := len(e.Input)
+	if i := strings.IndexByte(e.Input[e.Offset:], '\n'); i >= 0 {
+		end = e.Offset + i
+	}
+	var b strings.Builder
+	b.WriteString(e.Input[start:end])
+	b.WriteByte('\n')
+	// tabs are kept so carets line up with the source
+	for _, r := range e.Input[start:e.Offset] {
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	carets := 1
+	if e.End > e.Offset {
+		last := e.End
+		if last > end {
+			last = end
+		}
+		if n := utf8.RuneCountInString(e.Input[e.Offset:last]); n > carets {
+			carets = n
+		}
+	}
+	b.WriteString(strings.Repeat(`^`, carets))
+	return b.String()
+}
+
+// ExprErrors are errors found in one pass over an expression, in the order of checking.
+// errors.As finds the first one as *ExprError
+type ExprErrors []*ExprError
+
+func (errs ExprErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the first error
+func (errs ExprErrors) Unwrap() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
+
+// errorOfTree positions err at the whole expression of tree
+func errorOfTree(tree *Tree, category ErrorCategory, err error) error {
+	if tree == nil || err == nil {
+		return err
+	}
+	return ExprErrors{newExprError(tree.input, tree.root.pos(), category, err)}
+}
@@ -0,0 +1,79 @@
+package expr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/EchoUtopia/zerror"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExprError(t *testing.T) {
+	cases := []struct {
+		input    string
+		category ErrorCategory
+		line     int
+		column   int
+		snippet  string
+	}{
+		{"$a = = 1", CategorySyntax, 1, 5, "$a = = 1\n     ^"},
+		{"$a in", CategorySyntax, 1, 5, "$a in\n     ^"},
+		{"$a = 'x", CategoryLexical, 1, 5, "$a = 'x\n     ^^"},
+		{"99999999999999999999 > 1", CategoryLexical, 1, 0, "99999999999999999999 > 1\n^^^^^^^^^^^^^^^^^^^^"},
+		{"$a[99999999999999999999] = 1", CategoryLexical, 1, 0, "$a[99999999999999999999] = 1\n^^^^^^^^^^^^^^^^^^^^^^^^"},
+		{"$a = 1 and\n\t'x' + 1 > 2", CategoryType, 2, 1, "\t'x' + 1 > 2\n\t^^^"},
+		{"$a = 'é' + 1", CategoryType, 1, 5, "$a = 'é' + 1\n     ^^^"},
+		{"1 + 2", CategoryType, 1, 0, "1 + 2\n^^^^^"},
+		{"$a and unknown()", CategoryFunction, 1, 7, "$a and unknown()\n       ^^^^^^^"},
+		{"length(1, 2) > 1", CategoryFunction, 1, 0, "length(1, 2) > 1\n^^^^^^^^^^^^"},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.input)
+		var e *ExprError
+		require.True(t, errors.As(err, &e), c.input)
+		require.True(t, zerror.BadRequest.Cause(err), c.input)
+		require.Equal(t, c.category, e.Category, c.input)
+		require.Equal(t, c.line, e.Line, c.input)
+		require.Equal(t, c.column, e.Column, c.input)
+		require.Equal(t, c.snippet, e.Snippet(), c.input)
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	_, err := Parse(`$a # 1 @ = 2`)
+	require.Equal(t, "line 1:3 token recognition error at: '#'\nline 1:7 token recognition error at: '@'", err.Error())
+
+	// parsing recovers from syntax errors at and, or, commas and closing brackets
+	_, err = Parse(`$a > > 1 and f(1 +, [2 *]) or ($b = )`)
+	require.Equal(t, "line 1:5 mismatched input '>'\nline 1:18 mismatched input ','\nline 1:24 mismatched input ']'\nline 1:36 mismatched input ')'", err.Error())
+	_, err = Parse(`f(1 2) and $b <`)
+	require.Equal(t, "line 1:4 mismatched input '2' expecting ')'\nline 1:15 mismatched input '<EOF>'", err.Error())
+
+	_, err = Parse(`'a' + 1 > 2 and unknown($a) and startsWith(1, $b)`)
+	errs, ok := err.(ExprErrors)
+	require.True(t, ok)
+	require.Len(t, errs, 3)
+	require.Equal(t, ExprError{
+		Category: CategoryType, Msg: `can not do math operation with type: (string)'a'`, Input: errs[0].Input,
+		Offset: 0, End: 3, Line: 1, Column: 0, EndLine: 1, EndColumn: 3, err: errs[0].err,
+	}, *errs[0])
+	require.Equal(t, `func: unknown not found`, errs[1].Msg)
+	require.Equal(t, CategoryFunction, errs[1].Category)
+	require.Equal(t, `func: startsWith, arg position: 0 expect string, got int64`, errs[2].Msg)
+	require.Equal(t, 43, errs[2].Offset)
+}
+
+func TestTooManyArgs(t *testing.T) {
+	args := strings.Repeat(`'a', `, 300) + `'a'`
+	_, err := Evaluate(`length(concat(`+args+`)) > 1`, nil)
+	var e *ExprError
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, CategoryFunction, e.Category)
+	require.Equal(t, `func: concat expect at most 255 args, 301 got`, e.Msg)
+	require.Equal(t, 7, e.Offset)
+
+	result, err := Evaluate(`length(concat(`+strings.Repeat(`'a', `, 254)+`'a')) = 255`, nil)
+	require.Nil(t, err)
+	require.True(t, result)
+}
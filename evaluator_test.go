@@ -3,6 +3,9 @@ package expr
 import (
 	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -153,6 +156,11 @@ func TestFunctionEvaluator(t *testing.T) {
 		`length('abc')=3`,
 		`toLower('ABC')='abc'`,
 		`toUpper($string)='STR'`,
+		`startsWith(toLower(toUpper($string)), 's')`,
+		`length(concat($string, concat('a', 'b'))) > 3`,
+		`testArgs($int * 2 - 1, $float / 2, $int > 0 and $true, trim(concat(' ', $string)))`,
+		`length([1, $int, 3] ) = 3 and length({'a': $string}) = 1`,
+		`contains(concat($string, toUpper($string)), 'rS')`,
 	}
 
 	for _, v := range trues {
@@ -180,12 +188,9 @@ func TestFunctionEvaluator(t *testing.T) {
 		`testArgs($int, $int, $xxx, $int)`,
 	}
 	for _, expr := range invalids {
-		parser := NewParser()
-		tree, err := parser.Parse(expr)
+		program, err := NewParser().Compile(expr)
 		require.Equalf(t, nil, err, expr)
-		evaluator, err := NewEvaluatorWithParser(tree, parser, vars)
-		require.Equalf(t, nil, err, expr)
-		_, err = evaluator.Evaluate()
+		_, err = program.Eval(vars)
 		require.NotEqualf(t, nil, err, expr)
 	}
 }
@@ -205,20 +210,231 @@ func TestFuncError(t *testing.T) {
 		`error()`,
 	}
 	for _, v := range exprs {
-		tree, err := parser.Parse(v)
+		program, err := parser.Compile(v)
 		if err != nil {
 			t.Fatal(err)
 		}
-		ev, err := NewEvaluatorWithParser(tree, parser, nil)
-		if err != nil {
-			t.Fatal(err)
-		}
-		result, err := ev.Evaluate()
+		result, err := program.Eval(nil)
 		require.Equal(t, false, result, v)
 		require.NotNil(t, err, v)
 	}
 }
 
+func TestVariadicFunctions(t *testing.T) {
+	parser := NewParser()
+	coalesce := func(xs ...string) string {
+		for _, x := range xs {
+			if x != `` {
+				return x
+			}
+		}
+		return ``
+	}
+	require.Nil(t, parser.RegisterFunc(`coalesce`, coalesce))
+	sum := func(base int64, xs ...float64) float64 {
+		for _, x := range xs {
+			base += int64(x)
+		}
+		return float64(base)
+	}
+	require.Nil(t, parser.RegisterFunc(`sum`, sum))
+	truncate := func(s string, limit int64, suffix string) string {
+		if int64(len(s)) <= limit {
+			return s
+		}
+		return s[:limit] + suffix
+	}
+	require.Nil(t, parser.RegisterFunc(`truncate`, truncate, WithDefaults(int64(3), `...`)))
+
+	vars := map[string]interface{}{`empty`: ``, `name`: `bob`, `int`: 2, `float`: 2.0}
+	trues := []string{
+		`coalesce($empty, '', $name, 'x') = 'bob'`,
+		`coalesce() = ''`,
+		`concat('a', 'b', $name, concat()) = 'abbob'`,
+		`sum(1) = 1 and sum(1, 2.0, $float, 3.5) = 8`,
+		`truncate('abcdef') = 'abc...'`,
+		`truncate('abcdef', 4) = 'abcd...'`,
+		`truncate('abcdef', $int, '') = 'ab'`,
+	}
+	for _, input := range trues {
+		program, err := parser.Compile(input)
+		require.Nil(t, err, input)
+		result, err := program.Eval(vars)
+		require.Nil(t, err, input)
+		require.True(t, result, input)
+	}
+
+	invalids := map[string]string{
+		`coalesce($empty, 1) = ''`:     `arg position: 1 expect string, got int64`,
+		`sum() = 1`:                    `expect at least 1 args, 0 got`,
+		`sum(1, 'a') = 1`:              `arg position: 1 expect float64, got string`,
+		`truncate() = ''`:              `expect 1 to 3 args, 0 got`,
+		`truncate('a', 1, '', 1) = ''`: `expect 1 to 3 args, 4 got`,
+	}
+	for input, msg := range invalids {
+		_, err := parser.Compile(input)
+		require.NotNil(t, err, input)
+		require.Contains(t, err.Error(), msg, input)
+	}
+
+	require.NotNil(t, parser.RegisterFunc(`d1`, truncate, WithDefaults(1, 2, 3, 4)))
+	require.NotNil(t, parser.RegisterFunc(`d2`, truncate, WithDefaults(`a`, `b`)))
+	require.NotNil(t, parser.RegisterFunc(`d3`, coalesce, WithDefaults(`a`)))
+}
+
+func TestInterfaceResultsAsBools(t *testing.T) {
+	env := NewEnv()
+	require.Nil(t, env.RegisterFunc(`get`, func(m map[string]interface{}, k string) interface{} { return m[k] }))
+	vars := map[string]interface{}{`m`: map[string]interface{}{`ok`: true, `no`: false, `n`: 1}}
+	for input, want := range map[string]bool{
+		`get($m, 'ok') and true`: true,
+		`get($m, 'no') or $m.ok`: true,
+		`!get($m, 'no')`:         true,
+	} {
+		result, err := env.Evaluate(input, vars)
+		require.Nil(t, err, input)
+		require.Equal(t, want, result, input)
+	}
+	// results of interface{} are checked at runtime like variables
+	_, err := env.Evaluate(`get($m, 'n') and true`, vars)
+	require.Contains(t, err.Error(), `expect bool result, got int64: 1`)
+	_, err = env.Compile(`length('a') and true`)
+	require.Contains(t, err.Error(), `expect func: length return bool`)
+}
+
+func TestFuncConversions(t *testing.T) {
+	parser := NewParser()
+	funcs := map[string]interface{}{
+		`add8`:     func(a, b int8) int8 { return a + b },
+		`half`:     func(f float32) float32 { return f / 2 },
+		`echoUint`: func(u uint) uint { return u },
+		`maxUint`:  func() uint64 { return math.MaxUint64 },
+		`join`:     strings.Join,
+		`sumInts`: func(xs []int) int {
+			sum := 0
+			for _, x := range xs {
+				sum += x
+			}
+			return sum
+		},
+		`keys`: func(m map[string]int) []string {
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return keys
+		},
+		`typeOf`: func(x interface{}) string { return fmt.Sprintf(`%T`, x) },
+		`year`: func(x interface{}) (int, error) {
+			t, ok := x.(time.Time)
+			if !ok {
+				return 0, errors.New(`not time`)
+			}
+			return t.Year(), nil
+		},
+	}
+	for name, fn := range funcs {
+		require.Nil(t, parser.RegisterFunc(name, fn), name)
+	}
+	vars := map[string]interface{}{
+		`int`:   2,
+		`roles`: []string{`a`, `b`},
+		`ids`:   []int32{1, 2},
+		`m`:     map[string]interface{}{`x`: 1, `y`: int8(2)},
+		`t`:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		`user`:  map[string]interface{}{`born`: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	trues := []string{
+		`add8(100, 27) = 127`,
+		`add8($int, -1) = 1`,
+		`half(3) = 1.5 and half($int) = 1`,
+		`echoUint($int) = 2`,
+		`join($roles, ',') = 'a,b'`,
+		`join(['x', concat('y', 'z')], '-') = 'x-yz'`,
+		`sumInts([1, 2, $int]) = 5 and sumInts($ids) = 3 and sumInts([]) = 0`,
+		`'y' in keys($m) and length(keys({'a': 1, 'b': $int})) = 2`,
+		`typeOf($int) = 'int' and typeOf($ids) = '[]int32' and typeOf($int + 1) = 'int64'`,
+		`year($t) = 2020 and year($user.born) < year($t)`,
+	}
+	for _, input := range trues {
+		program, err := parser.Compile(input)
+		require.Nil(t, err, input)
+		result, err := program.Eval(vars)
+		require.Nil(t, err, input)
+		require.True(t, result, input)
+	}
+
+	runtimeErrors := map[string]string{
+		`add8(200, 1) = 1`:         `func: add8, arg position: 0 expect int8, got int64: (int64)200 overflows int8`,
+		`echoUint(-1) = 1`:         `(int64)-1 overflows uint`,
+		`maxUint() = 1`:            `(uint64)18446744073709551615 overflows int64`,
+		`join(['a', 1], '') = 'a'`: `can not convert (int64)1 to string`,
+		`sumInts([1.5]) = 1`:       `can not convert (float64)1.5 to int`,
+		`year($int) = 1`:           `not time`,
+	}
+	for input, msg := range runtimeErrors {
+		program, err := parser.Compile(input)
+		require.Nil(t, err, input)
+		_, err = program.Eval(vars)
+		require.NotNil(t, err, input)
+		require.Contains(t, err.Error(), msg, input)
+		require.LessOrEqual(t, strings.Count(err.Error(), `zerror:`), 1, input)
+	}
+
+	invalids := []string{
+		`add8(1.5, 1) = 1`,
+		`join('a', 'b') = 'a'`,
+		`sumInts({'a': 1}) = 1`,
+		`keys([1]) = 1`,
+		`half('a') = 1`,
+	}
+	for _, input := range invalids {
+		_, err := parser.Compile(input)
+		require.NotNil(t, err, input)
+	}
+}
+
+func TestShortCircuit(t *testing.T) {
+	parser := NewParser()
+	called := 0
+	count := func() bool {
+		called++
+		return true
+	}
+	require.Nil(t, parser.RegisterFunc(`count`, count))
+	fails := func() (bool, error) { return false, errors.New(`should not be called`) }
+	require.Nil(t, parser.RegisterFunc(`fails`, fails))
+
+	cases := []struct {
+		expr   string
+		result bool
+		called int
+	}{
+		{`false and count()`, false, 0},
+		{`true or count()`, true, 0},
+		{`$false and count() and count()`, false, 0},
+		{`$true or fails()`, true, 0},
+		{`true and count()`, true, 1},
+		{`false or count()`, true, 1},
+		{`count() and (false and fails() or count())`, true, 2},
+		{`(true or fails()) and !(false and fails())`, true, 0},
+		{`$false and $missing`, false, 0},
+		{`1 > 2 and fails2() = 'x' or true`, true, 0},
+	}
+	require.Nil(t, parser.RegisterFunc(`fails2`, func() (string, error) { return ``, errors.New(`fails2`) }))
+	vars := map[string]interface{}{`true`: true, `false`: false}
+	for _, c := range cases {
+		called = 0
+		program, err := parser.Compile(c.expr)
+		require.Nil(t, err, c.expr)
+		result, err := program.Eval(vars)
+		require.Nil(t, err, c.expr)
+		require.Equal(t, c.result, result, c.expr)
+		require.Equal(t, c.called, called, c.expr)
+	}
+}
+
 func testEvaluator(t *testing.T, expr string, vars map[string]interface{}, expect bool) {
 	result, err := Evaluate(expr, vars)
 	require.Equal(t, nil, err, expr, err)
@@ -229,13 +445,25 @@ func TestCheckVariables(t *testing.T) {
 	invalids := map[string]interface{}{
 		``:   ``,
 		`t1`: uint64(1),
-		`t2`: struct{}{},
-		`t3`: time.Now(),
+		`t2`: func() {},
+		`t3`: make(chan int),
 	}
 	for k, v := range invalids {
-		require.NotEqual(t, nil, checkSetVariables(map[string]interface{}{
-			k: v,
-		}))
+		_, err := normalizeVariable(k, v)
+		require.NotEqual(t, nil, err)
+	}
+	_, err := normalizeVariable(``, 1)
+	require.Equal(t, `empty variable name`, errorMessage(err))
+	// fields of structs, maps and slices are got by paths
+	valids := map[string]interface{}{
+		`t1`: struct{}{},
+		`t2`: time.Now(),
+		`t3`: map[string]interface{}{},
+		`t4`: []string{},
+	}
+	for k, v := range valids {
+		_, err := normalizeVariable(k, v)
+		require.Nil(t, err)
 	}
 }
 
@@ -264,7 +492,7 @@ func BenchmarkEvaluate(b *testing.B) {
 
 }
 
-func ExampleParseAndEvaluate() {
+func ExampleRegisterFunc() {
 	expr := `$car in ('bwm', 'byd') and (3 + echo_int(2)) * 2.0 = 10 and startsWith($car, 'b')`
 	var tInt = func(a int64) int64 {
 		return a
@@ -1,7 +1,6 @@
 package expr
 
 import (
-	"github.com/antlr/antlr4/runtime/Go/antlr"
 	"reflect"
 	"testing"
 )
@@ -10,7 +9,7 @@ func parse(withCache bool) bool {
 	input := `(3+2)*2 >= 1`
 	//input = `'s' in ('a')`
 	parser := NewParser()
-	var tree antlr.Tree
+	var tree *Tree
 	var err error
 	if withCache {
 		tree, err = parser.ParseWithCache(input)
@@ -20,8 +19,11 @@ func parse(withCache bool) bool {
 	if err != nil {
 		panic(err)
 	}
-	evaluator, _ := NewEvaluatorWithParser(tree, parser, nil)
-	result, err := evaluator.Evaluate()
+	program, err := parser.compileTree(input, tree)
+	if err != nil {
+		panic(err)
+	}
+	result, err := program.Eval(nil)
 	if err != nil {
 		panic(err)
 	}
@@ -40,6 +42,54 @@ func BenchmarkEvaluateWithCache(b *testing.B) {
 	}
 }
 
+var benchExprs = map[string]string{
+	`Math`:     `(3+2)*2 >= 1`,
+	`Vars`:     `$a * 2 + $b > 10.5 and $c != 'x'`,
+	`In`:       `$car in ('bwm', 'byd', 'audi') and $n not in (1, 2, 3)`,
+	`Function`: `startsWith($car, 'b') and length($car) = 3`,
+}
+
+var benchVars = map[string]interface{}{
+	`a`:   int64(5),
+	`b`:   1.5,
+	`c`:   `y`,
+	`car`: `byd`,
+	`n`:   int64(4),
+}
+
+func BenchmarkProgramEval(b *testing.B) {
+	for name, input := range benchExprs {
+		program, err := Compile(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				result, err := program.Eval(benchVars)
+				if err != nil || !result {
+					b.Fatal(result, err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkProgramEvalParallel(b *testing.B) {
+	program, err := Compile(benchExprs[`Vars`])
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := program.Eval(benchVars); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
 func BenchmarkReflectValue(b *testing.B) {
 	a := 1
 	for i := 0; i < b.N; i++ {
@@ -47,3 +97,22 @@ func BenchmarkReflectValue(b *testing.B) {
 		_ = va.Int()
 	}
 }
+
+func BenchmarkParse(b *testing.B) {
+	parsers := map[string]func(string) (*Tree, error){
+		`Pratt`: parseSyntax,
+		`ANTLR`: parseANTLR,
+	}
+	for name, input := range benchExprs {
+		for parserName, parse := range parsers {
+			b.Run(name+`/`+parserName, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := parse(input); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
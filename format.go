@@ -0,0 +1,147 @@
+package expr
+
+import (
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/EchoUtopia/expr/ast"
+)
+
+// operators of nodes and ast have the same order
+var astOperators = [...]ast.Operator{
+	operatorNot: ast.Not,
+	operatorNeg: ast.Neg,
+	operatorAnd: ast.And,
+	operatorOr:  ast.Or,
+	operatorEQ:  ast.EQ,
+	operatorNEQ: ast.NEQ,
+	operatorGT:  ast.GT,
+	operatorGTE: ast.GTE,
+	operatorLT:  ast.LT,
+	operatorLTE: ast.LTE,
+	operatorAdd: ast.Add,
+	operatorSub: ast.Sub,
+	operatorMul: ast.Mul,
+	operatorDiv: ast.Div,
+	operatorMod: ast.Mod,
+	operatorPow: ast.Pow,
+}
+
+func astSpan(s span) ast.Span {
+	return ast.Span{Start: s.start, Stop: s.end}
+}
+
+// toAST converts nodes into the public ast, literal lists and maps become List and Map of literals
+func toAST(n node) ast.Node {
+	switch n := n.(type) {
+	case *literalNode:
+		if lit, ok := literalAST(n.span, n.val); ok {
+			return lit
+		}
+		return &ast.Literal{Span: astSpan(n.span), Value: n.val.interfaceValue()}
+	case *variableNode:
+		v := &ast.Var{Span: astSpan(n.span), Name: n.name}
+		for _, step := range n.path {
+			v.Path = append(v.Path, ast.Step{Field: step.field, Index: step.index, IsIndex: step.isIndex})
+		}
+		return v
+	case *identifierNode:
+		return &ast.Ident{Span: astSpan(n.span), Name: n.name}
+	case *unaryNode:
+		return &ast.Unary{Span: astSpan(n.span), Op: astOperators[n.op], X: toAST(n.x)}
+	case *binaryNode:
+		return &ast.BinaryOp{Span: astSpan(n.span), Op: astOperators[n.op], X: toAST(n.x), Y: toAST(n.y)}
+	case *inNode:
+		return &ast.In{Span: astSpan(n.span), Not: n.not, X: toAST(n.x), Y: toAST(n.y)}
+	case *listNode:
+		list := &ast.List{Span: astSpan(n.span), Elems: make([]ast.Node, len(n.elems))}
+		for i, elem := range n.elems {
+			list.Elems[i] = toAST(elem)
+		}
+		return list
+	case *mapNode:
+		m := &ast.Map{Span: astSpan(n.span), Keys: n.keys, Values: make([]ast.Node, len(n.values))}
+		for i, v := range n.values {
+			m.Values[i] = toAST(v)
+		}
+		return m
+	case *callNode:
+		call := &ast.Call{Span: astSpan(n.span), Name: n.name, Args: make([]ast.Node, len(n.args))}
+		for i, arg := range n.args {
+			call.Args[i] = toAST(arg)
+		}
+		return call
+	}
+	return nil
+}
+
+// literalAST converts v into literals, ok is false if v can't be written in expressions, like NaN and structs
+func literalAST(sp span, v value) (ast.Node, bool) {
+	switch v.kind {
+	case kindBool, kindInt, kindString:
+		return &ast.Literal{Span: astSpan(sp), Value: v.interfaceValue()}, true
+	case kindFloat:
+		if math.IsInf(v.f, 0) || math.IsNaN(v.f) {
+			return nil, false
+		}
+		return &ast.Literal{Span: astSpan(sp), Value: v.f}, true
+	case kindList:
+		rv := reflect.ValueOf(v.x)
+		list := &ast.List{Span: astSpan(sp), Elems: make([]ast.Node, rv.Len())}
+		for i := range list.Elems {
+			elem, ok := elementAST(sp, rv.Index(i).Interface())
+			if !ok {
+				return nil, false
+			}
+			list.Elems[i] = elem
+		}
+		return list, true
+	case kindMap:
+		rv := reflect.ValueOf(v.x)
+		m := &ast.Map{Span: astSpan(sp)}
+		for _, key := range rv.MapKeys() {
+			m.Keys = append(m.Keys, key.String())
+		}
+		sort.Strings(m.Keys)
+		for _, key := range m.Keys {
+			elem, ok := elementAST(sp, rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())).Interface())
+			if !ok {
+				return nil, false
+			}
+			m.Values = append(m.Values, elem)
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+func elementAST(sp span, i interface{}) (ast.Node, bool) {
+	v, err := valueOf(`element`, i)
+	if err != nil {
+		return nil, false
+	}
+	return literalAST(sp, v)
+}
+
+// formatNode formats n as an expression which parses to the same nodes
+func formatNode(n node) string {
+	return ast.Format(toAST(n))
+}
+
+// formatValue formats v as a literal, ok is false if v can't be written in expressions
+func formatValue(v value) (string, bool) {
+	lit, ok := literalAST(span{}, v)
+	if !ok {
+		return ``, false
+	}
+	return ast.Format(lit), true
+}
+
+// literal formats v like formatValue, values which can't be literals are formatted by String
+func (v value) literal() string {
+	if s, ok := formatValue(v); ok {
+		return s
+	}
+	return v.String()
+}
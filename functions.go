@@ -1,6 +1,7 @@
 package expr
 
 import (
+	"context"
 	"reflect"
 	"strings"
 	"time"
@@ -12,14 +13,194 @@ type function struct {
 	name string
 	iFn  interface{}
 	// the following will be set by register
-	// if argsNumber is -1, no limit set
+	// argsNumber counts the variadic parameter
 	argsNumber int
 	isVariadic bool
-	fn         reflect.Value
+	// defaults of the trailing parameters before the variadic one, see WithDefaults
+	defaults    []value
+	rawDefaults []interface{}
+	fn          reflect.Value
+	// the type of results in expressions, like int64 for all ints, see exprType
 	returnType reflect.Type
+	// the first parameter is context.Context, it's not counted in argsNumber
+	withContext bool
+	// cost of one call, see WithCost
+	cost int64
+	// see Pure
+	pure bool
 }
 
 var errorType = reflect.TypeOf((*error)(nil)).Elem()
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+func newFunction(name string, i interface{}, opts ...FuncOption) (*function, error) {
+	returnType, fnVal, err := checkFunction(name, i)
+	if err != nil {
+		return nil, err
+	}
+	fnType := fnVal.Type()
+	f := &function{
+		name:        name,
+		iFn:         i,
+		fn:          fnVal,
+		returnType:  returnType,
+		argsNumber:  fnType.NumIn(),
+		isVariadic:  fnType.IsVariadic(),
+		withContext: takesContext(fnType),
+	}
+	if f.withContext {
+		f.argsNumber--
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if err := f.setDefaults(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// setDefaults converts the values of WithDefaults to the types of the trailing parameters
+func (f *function) setDefaults() error {
+	fixed := f.fixedArgs()
+	if len(f.rawDefaults) > fixed {
+		return zerror.BadRequest.Errorf(`func: %s has %d parameters, %d defaults got`, f.name, fixed, len(f.rawDefaults))
+	}
+	f.defaults = make([]value, 0, len(f.rawDefaults))
+	for i, raw := range f.rawDefaults {
+		pos := fixed - len(f.rawDefaults) + i
+		v, err := valueOf(f.name, raw)
+		if err != nil {
+			return err
+		}
+		if expected := f.in(pos); !assignable(v.kind, expected) {
+			return zerror.BadRequest.Errorf(`func: %s, default of arg position: %d expect %s, got %s`, f.name, pos, expected, v.kind)
+		}
+		f.defaults = append(f.defaults, v)
+	}
+	return nil
+}
+
+// fixedArgs is the number of parameters in expressions, except the variadic one
+func (f *function) fixedArgs() int {
+	if f.isVariadic {
+		return f.argsNumber - 1
+	}
+	return f.argsNumber
+}
+
+// maxArgs is the max number of args of a call, the bytecode counts them in a byte
+const maxArgs = 255
+
+// checkArity checks the number of args, considering defaults and the variadic parameter
+func (f *function) checkArity(n int) error {
+	max := f.fixedArgs()
+	min := max - len(f.defaults)
+	switch {
+	case n > maxArgs:
+		return zerror.BadRequest.Errorf(`func: %s expect at most %d args, %d got`, f.name, maxArgs, n)
+	case n >= min && (n <= max || f.isVariadic):
+		return nil
+	case f.isVariadic:
+		return zerror.BadRequest.Errorf(`func: %s expect at least %d args, %d got`, f.name, min, n)
+	case min == max:
+		return zerror.BadRequest.Errorf(`func: %s expect %d args, %d got`, f.name, max, n)
+	}
+	return zerror.BadRequest.Errorf(`func: %s expect %d to %d args, %d got`, f.name, min, max, n)
+}
+
+func takesContext(t reflect.Type) bool {
+	return t.NumIn() > 0 && t.In(0) == contextType
+}
+
+// in returns the type of the i-th argument in expressions, arguments of the variadic parameter have its element type
+func (f *function) in(i int) reflect.Type {
+	t := f.fn.Type()
+	if f.withContext {
+		i++
+	}
+	if f.isVariadic && i >= t.NumIn()-1 {
+		return t.In(t.NumIn() - 1).Elem()
+	}
+	return t.In(i)
+}
+
+func (f *function) checkArgs(args []value) error {
+	if err := f.checkArity(len(args)); err != nil {
+		return err
+	}
+	for i, arg := range args {
+		expected := f.in(i)
+		if !assignable(arg.kind, expected) {
+			return zerror.BadRequest.Errorf(`func: %s, arg position: %d, expect: %s, got: %s`, f.name, i, expected, arg.kind)
+		}
+	}
+	return nil
+}
+
+// call checks args and calls the function, common signatures are called without reflect
+func (f *function) call(ctx context.Context, args []value) (value, error) {
+	if err := f.checkArgs(args); err != nil {
+		return value{}, err
+	}
+	if missing := f.fixedArgs() - len(args); missing > 0 {
+		args = append(args[:len(args):len(args)], f.defaults[len(f.defaults)-missing:]...)
+	}
+	switch fn := f.iFn.(type) {
+	case func() bool:
+		return boolValue(fn()), nil
+	case func() int64:
+		return intValue(fn()), nil
+	case func() float64:
+		return floatValue(fn()), nil
+	case func() string:
+		return stringValue(fn()), nil
+	case func(string) bool:
+		return boolValue(fn(args[0].s)), nil
+	case func(string) int64:
+		return intValue(fn(args[0].s)), nil
+	case func(string) string:
+		return stringValue(fn(args[0].s)), nil
+	case func(string, string) bool:
+		return boolValue(fn(args[0].s, args[1].s)), nil
+	case func(string, string) string:
+		return stringValue(fn(args[0].s, args[1].s)), nil
+	case func(int64) int64:
+		return intValue(fn(args[0].i)), nil
+	case func(float64) float64:
+		return floatValue(fn(args[0].float())), nil
+	case func(...string) string:
+		ss := make([]string, len(args))
+		for i, arg := range args {
+			ss[i] = arg.s
+		}
+		return stringValue(fn(ss...)), nil
+	case func(interface{}) (int64, error):
+		i, err := fn(args[0].interfaceValue())
+		if err != nil {
+			return value{}, err
+		}
+		return intValue(i), nil
+	}
+	in := make([]reflect.Value, 0, len(args)+1)
+	if f.withContext {
+		in = append(in, reflect.ValueOf(&ctx).Elem())
+	}
+	for i, arg := range args {
+		rv, err := convertTo(arg.interfaceValue(), f.in(i))
+		if err != nil {
+			return value{}, zerror.BadRequest.Errorf(`func: %s, arg position: %d expect %s, got %s: %s`, f.name, i, f.in(i), arg.kind, errorMessage(err))
+		}
+		in = append(in, rv)
+	}
+	outs := f.fn.Call(in)
+	if len(outs) == 2 {
+		if err, _ := outs[1].Interface().(error); err != nil {
+			return value{}, err
+		}
+	}
+	return valueOfReflect(outs[0])
+}
 
 func checkFunction(name string, i interface{}) (reflect.Type, reflect.Value, error) {
 	v := reflect.ValueOf(i)
@@ -27,17 +208,23 @@ func checkFunction(name string, i interface{}) (reflect.Type, reflect.Value, err
 	if t.Kind() != reflect.Func {
 		return nil, reflect.Value{}, zerror.BadRequest.Errorf(`[%s] is not func`, name)
 	}
-	// TODO: support variadic
-	if t.IsVariadic() {
-		return nil, reflect.Value{}, zerror.BadRequest.WithMsg(`variadic function not supported`)
+	start := 0
+	if takesContext(t) {
+		start = 1
 	}
-	for i := 0; i < t.NumIn(); i++ {
-		nik := t.In(i).Kind()
-		if nik != reflect.Int64 && nik != reflect.Float64 && nik != reflect.Bool && nik != reflect.String {
-			return nil, reflect.Value{}, zerror.BadRequest.WithMsg(`function variables only support float64, int64, string and bool`)
+	for i := start; i < t.NumIn(); i++ {
+		in := t.In(i)
+		// the element type of the variadic parameter is checked
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in = in.Elem()
+		}
+		if !isParamType(in) {
+			return nil, reflect.Value{}, zerror.BadRequest.Errorf(`function parameters only support numbers, string, bool, interface{}, slices and maps with string keys of them, got %s, except the first one can be context.Context`, in)
 		}
 	}
-	if t.NumOut() > 2 {
+	if t.NumOut() == 0 {
+		return nil, reflect.Value{}, zerror.BadRequest.WithMsg(`function must return a value`)
+	} else if t.NumOut() > 2 {
 		return nil, reflect.Value{}, zerror.BadRequest.WithMsg(`function returns more than 2 vars`)
 	} else if t.NumOut() == 2 {
 		if t.Out(1) != errorType {
@@ -48,11 +235,9 @@ func checkFunction(name string, i interface{}) (reflect.Type, reflect.Value, err
 			return nil, reflect.Value{}, zerror.BadRequest.WithMsg(`func first return var can not be error`)
 		}
 	}
-	returnType := t.Out(0)
-	returnKind := returnType.Kind()
-	returnBool := returnKind == reflect.Bool
-	if !returnBool && returnKind != reflect.Int64 && returnKind != reflect.Float64 && returnKind != reflect.String {
-		return nil, reflect.Value{}, zerror.BadRequest.WithMsg(`function first return val type must be one of string, int64, float64 and bool`)
+	returnType := exprType(t.Out(0))
+	if returnType == nil {
+		return nil, reflect.Value{}, zerror.BadRequest.Errorf(`function first return val type must be one of numbers, string, bool, interface{}, slices and maps with string keys, got %s`, t.Out(0))
 	}
 	return returnType, v, nil
 }
@@ -69,6 +254,11 @@ var builtinFuncs = map[string]interface{}{
 	`now`:        now,
 }
 
+// builtin functions are pure except them
+var impureBuiltins = map[string]bool{
+	`now`: true,
+}
+
 func now() string {
 	return time.Now().Format(time.RFC3339)
 }
@@ -85,8 +275,17 @@ func startsWith(s, prefix string) bool {
 	return strings.HasPrefix(s, prefix)
 }
 
-func length(s string) int64 {
-	return int64(len(s))
+// length returns the length of strings, lists and maps
+func length(v interface{}) (int64, error) {
+	if s, ok := v.(string); ok {
+		return int64(len(s)), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return int64(rv.Len()), nil
+	}
+	return 0, zerror.BadRequest.Errorf(`length of %T is not supported`, v)
 }
 
 func toLower(s string) string {
@@ -101,6 +300,6 @@ func trim(s string) string {
 	return strings.TrimSpace(s)
 }
 
-func concat(sa, sb string) string {
-	return sa + sb
+func concat(ss ...string) string {
+	return strings.Join(ss, ``)
 }
@@ -0,0 +1,349 @@
+package expr
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/EchoUtopia/expr/ast"
+	"github.com/EchoUtopia/zerror"
+)
+
+// JSONLogic (jsonlogic.com) operations and the operators of expressions
+var jsonLogicOperators = map[string]ast.Operator{
+	`==`: ast.EQ, `===`: ast.EQ, `!=`: ast.NEQ, `!==`: ast.NEQ, `>`: ast.GT, `>=`: ast.GTE, `<`: ast.LT, `<=`: ast.LTE,
+	`+`: ast.Add, `-`: ast.Sub, `*`: ast.Mul, `/`: ast.Div, `%`: ast.Mod, `and`: ast.And, `or`: ast.Or,
+}
+
+var operatorsJSONLogic = map[ast.Operator]string{
+	ast.EQ: `==`, ast.NEQ: `!=`, ast.GT: `>`, ast.GTE: `>=`, ast.LT: `<`, ast.LTE: `<=`,
+	ast.Add: `+`, ast.Sub: `-`, ast.Mul: `*`, ast.Div: `/`, ast.Mod: `%`, ast.And: `and`, ast.Or: `or`,
+}
+
+// FromJSONLogic converts a JSONLogic rule into an expression.
+// ==, !=, comparisons, and, or, !, var, arithmetic and in are operators and variables, cat is concat,
+// and builtin functions are taken as custom operations of the same names, like {"startsWith": [{"var": "a"}, "x"]}.
+// `in` with a string literal is substring, like {"in": [{"var": "a"}, "xyz"]} is contains('xyz', $a),
+// otherwise it's `x in y`, like {"in": ["x", {"var": "tags"}]} is 'x' in $tags, which fails if tags is a string.
+// / is float division, like {"/": [{"var": "a"}, 2]} is $a / 2.0 and {"/": [{"var": "a"}, {"var": "b"}]} is $a * 1.0 / $b.
+// JSONLogic compares loosely, so rules are better to compare values of the same types
+func FromJSONLogic(rule []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(rule))
+	decoder.UseNumber()
+	var i interface{}
+	if err := decoder.Decode(&i); err != nil {
+		return ``, zerror.BadRequest.Wrapf(err, `jsonlogic: invalid rule`)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return ``, zerror.BadRequest.Errorf(`jsonlogic: invalid rule: trailing data`)
+	}
+	n, err := jsonLogicNode(i)
+	if err != nil {
+		return ``, err
+	}
+	return ast.Format(n), nil
+}
+
+func jsonLogicNode(i interface{}) (ast.Node, error) {
+	switch i := i.(type) {
+	case bool, string:
+		return &ast.Literal{Value: i}, nil
+	case json.Number:
+		if v, err := strconv.ParseInt(string(i), 10, 64); err == nil {
+			return &ast.Literal{Value: v}, nil
+		}
+		v, err := i.Float64()
+		if err != nil {
+			return nil, zerror.BadRequest.Errorf(`jsonlogic: invalid number: %s`, i)
+		}
+		return &ast.Literal{Value: v}, nil
+	case []interface{}:
+		list := &ast.List{Elems: make([]ast.Node, len(i))}
+		for k, elem := range i {
+			n, err := jsonLogicNode(elem)
+			if err != nil {
+				return nil, err
+			}
+			list.Elems[k] = n
+		}
+		return list, nil
+	case map[string]interface{}:
+		if len(i) != 1 {
+			return nil, zerror.BadRequest.Errorf(`jsonlogic: operations must have one key, got %d`, len(i))
+		}
+		for op, args := range i {
+			// a single argument can be written without the array
+			list, ok := args.([]interface{})
+			if !ok {
+				list = []interface{}{args}
+			}
+			return jsonLogicOperation(op, list)
+		}
+	}
+	return nil, zerror.BadRequest.Errorf(`jsonlogic: %v is not supported`, i)
+}
+
+func jsonLogicOperation(op string, args []interface{}) (ast.Node, error) {
+	if op == `var` {
+		return jsonLogicVar(args)
+	}
+	nodes := make([]ast.Node, len(args))
+	for k, arg := range args {
+		n, err := jsonLogicNode(arg)
+		if err != nil {
+			return nil, err
+		}
+		nodes[k] = n
+	}
+	arity := func(count int) error {
+		if len(nodes) != count {
+			return zerror.BadRequest.Errorf(`jsonlogic: operation: %s takes %d arguments, got %d`, op, count, len(nodes))
+		}
+		return nil
+	}
+	switch op {
+	case `!`:
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		// {"!": {"in": ...}} is not in
+		if in, ok := nodes[0].(*ast.In); ok {
+			return &ast.In{Not: !in.Not, X: in.X, Y: in.Y}, nil
+		}
+		return &ast.Unary{Op: ast.Not, X: nodes[0]}, nil
+	case `-`:
+		if len(nodes) == 1 {
+			return &ast.Unary{Op: ast.Neg, X: nodes[0]}, nil
+		}
+	case `<`, `<=`:
+		// between, like {"<": [1, {"var": "a"}, 3]} is 1 < $a and $a < 3
+		if len(nodes) == 3 {
+			return &ast.BinaryOp{
+				Op: ast.And,
+				X:  &ast.BinaryOp{Op: jsonLogicOperators[op], X: nodes[0], Y: nodes[1]},
+				Y:  &ast.BinaryOp{Op: jsonLogicOperators[op], X: nodes[1], Y: nodes[2]},
+			}, nil
+		}
+	case `in`:
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		if isStringLiteral(nodes[1]) {
+			return &ast.Call{Name: `contains`, Args: []ast.Node{nodes[1], nodes[0]}}, nil
+		}
+		return &ast.In{X: nodes[0], Y: nodes[1]}, nil
+	case `cat`:
+		return &ast.Call{Name: `concat`, Args: nodes}, nil
+	}
+	if o, ok := jsonLogicOperators[op]; ok {
+		// and, or, + and * take many arguments
+		if o == ast.And || o == ast.Or || o == ast.Add || o == ast.Mul {
+			if len(nodes) < 2 && (o == ast.Add || o == ast.Mul) || len(nodes) == 0 {
+				return nil, zerror.BadRequest.Errorf(`jsonlogic: operation: %s takes more arguments, got %d`, op, len(nodes))
+			}
+			n := nodes[0]
+			for _, y := range nodes[1:] {
+				n = &ast.BinaryOp{Op: o, X: n, Y: y}
+			}
+			return n, nil
+		}
+		if err := arity(2); err != nil {
+			return nil, err
+		}
+		if o == ast.Div {
+			return jsonLogicDiv(nodes[0], nodes[1]), nil
+		}
+		return &ast.BinaryOp{Op: o, X: nodes[0], Y: nodes[1]}, nil
+	}
+	if _, ok := builtinFuncs[op]; ok {
+		return &ast.Call{Name: op, Args: nodes}, nil
+	}
+	return nil, zerror.BadRequest.Errorf(`jsonlogic: operation: %s is not supported`, op)
+}
+
+// jsonLogicDiv converts / into float division, as / of expressions truncates integers:
+// an integer literal becomes a float one, otherwise x is promoted by x * 1.0
+func jsonLogicDiv(x, y ast.Node) ast.Node {
+	if lit, ok := y.(*ast.Literal); ok {
+		if i, ok := lit.Value.(int64); ok {
+			return &ast.BinaryOp{Op: ast.Div, X: x, Y: &ast.Literal{Value: float64(i)}}
+		}
+	}
+	if lit, ok := x.(*ast.Literal); ok {
+		if i, ok := lit.Value.(int64); ok {
+			return &ast.BinaryOp{Op: ast.Div, X: &ast.Literal{Value: float64(i)}, Y: y}
+		}
+	}
+	return &ast.BinaryOp{Op: ast.Div, X: &ast.BinaryOp{Op: ast.Mul, X: x, Y: &ast.Literal{Value: 1.0}}, Y: y}
+}
+
+// jsonLogicVar converts {"var": "a.b.0"} into $a.b[0], defaults are not supported
+func jsonLogicVar(args []interface{}) (ast.Node, error) {
+	if len(args) != 1 {
+		return nil, zerror.BadRequest.Errorf(`jsonlogic: var with defaults is not supported`)
+	}
+	var name string
+	switch arg := args[0].(type) {
+	case string:
+		name = arg
+	case json.Number:
+		name = arg.String()
+	}
+	parts := strings.Split(name, `.`)
+	if !isVariableName(parts[0]) {
+		return nil, zerror.BadRequest.Errorf(`jsonlogic: invalid var: %v`, args[0])
+	}
+	v := &ast.Var{Name: parts[0]}
+	for _, part := range parts[1:] {
+		if index, err := strconv.Atoi(part); err == nil && index >= 0 {
+			v.Path = append(v.Path, ast.Step{Index: index, IsIndex: true})
+			continue
+		}
+		if !isVariableName(part) {
+			return nil, zerror.BadRequest.Errorf(`jsonlogic: invalid var: %v`, args[0])
+		}
+		v.Path = append(v.Path, ast.Step{Field: part})
+	}
+	return v, nil
+}
+
+func isStringLiteral(n ast.Node) bool {
+	lit, ok := n.(*ast.Literal)
+	if !ok {
+		return false
+	}
+	_, ok = lit.Value.(string)
+	return ok
+}
+
+func isVariableName(s string) bool {
+	if s == `` || !isIdentStart(s[0]) {
+		return false
+	}
+	return lexIdent(s, 0) == len(s)
+}
+
+// ToJSONLogic converts expr into a JSONLogic rule, which can be encoded to JSON, see FromJSONLogic for the mapping,
+// contains of strings other than literals is the custom operation contains, as `in` would be list membership.
+// `**`, identifiers and functions other than builtin ones have no equivalent
+func ToJSONLogic(expr string) (interface{}, error) {
+	n, err := ParseAST(expr)
+	if err != nil {
+		return nil, err
+	}
+	return jsonLogicOf(n)
+}
+
+// ToJSONLogic converts the optimized tree of the program like the package level ToJSONLogic
+func (p *Program) ToJSONLogic() (interface{}, error) {
+	return jsonLogicOf(p.AST())
+}
+
+func jsonLogicOf(n ast.Node) (interface{}, error) {
+	switch n := n.(type) {
+	case *ast.Literal:
+		return n.Value, nil
+	case *ast.Var:
+		var b strings.Builder
+		b.WriteString(n.Name)
+		for _, step := range n.Path {
+			b.WriteString(`.`)
+			if step.IsIndex {
+				b.WriteString(strconv.Itoa(step.Index))
+			} else {
+				b.WriteString(step.Field)
+			}
+		}
+		return map[string]interface{}{`var`: b.String()}, nil
+	case *ast.Unary:
+		x, err := jsonLogicOf(n.X)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{n.Op.String(): []interface{}{x}}, nil
+	case *ast.BinaryOp:
+		op, ok := operatorsJSONLogic[n.Op]
+		if !ok {
+			break
+		}
+		operands := []ast.Node{n.X, n.Y}
+		// x * 1.0 / y is the float division of FromJSONLogic
+		if m, ok := n.X.(*ast.BinaryOp); ok && n.Op == ast.Div && m.Op == ast.Mul {
+			if lit, ok := m.Y.(*ast.Literal); ok && lit.Value == 1.0 {
+				operands[0] = m.X
+			}
+		}
+		if n.Op == ast.And || n.Op == ast.Or {
+			operands = flattenAST(n)
+		}
+		return jsonLogicOperands(op, operands)
+	case *ast.In:
+		x, err := jsonLogicOf(n.X)
+		if err != nil {
+			return nil, err
+		}
+		var y interface{}
+		if m, ok := n.Y.(*ast.Map); ok {
+			// the keys of maps
+			keys := make([]interface{}, len(m.Keys))
+			for i, key := range m.Keys {
+				keys[i] = key
+			}
+			y = keys
+		} else if y, err = jsonLogicOf(n.Y); err != nil {
+			return nil, err
+		}
+		in := map[string]interface{}{`in`: []interface{}{x, y}}
+		if n.Not {
+			return map[string]interface{}{`!`: []interface{}{in}}, nil
+		}
+		return in, nil
+	case *ast.List:
+		return jsonLogicList(n.Elems)
+	case *ast.Call:
+		switch _, ok := builtinFuncs[n.Name]; {
+		case n.Name == `contains` && isStringLiteral(n.Args[0]):
+			return jsonLogicOperands(`in`, []ast.Node{n.Args[1], n.Args[0]})
+		case n.Name == `concat`:
+			return jsonLogicOperands(`cat`, n.Args)
+		case ok:
+			return jsonLogicOperands(n.Name, n.Args)
+		}
+	}
+	return nil, zerror.BadRequest.Errorf(`jsonlogic: %s has no equivalent`, ast.Format(n))
+}
+
+func jsonLogicOperands(op string, operands []ast.Node) (interface{}, error) {
+	args, err := jsonLogicList(operands)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{op: args}, nil
+}
+
+func jsonLogicList(nodes []ast.Node) ([]interface{}, error) {
+	list := make([]interface{}, len(nodes))
+	for i, n := range nodes {
+		v, err := jsonLogicOf(n)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = v
+	}
+	return list, nil
+}
+
+// flattenAST collects operands of nested and, or or, like flatten
+func flattenAST(n *ast.BinaryOp) []ast.Node {
+	var operands []ast.Node
+	for _, x := range []ast.Node{n.X, n.Y} {
+		if b, ok := x.(*ast.BinaryOp); ok && b.Op == n.Op {
+			operands = append(operands, flattenAST(b)...)
+			continue
+		}
+		operands = append(operands, x)
+	}
+	return operands
+}
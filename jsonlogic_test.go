@@ -0,0 +1,313 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/EchoUtopia/expr/ast"
+	"github.com/stretchr/testify/require"
+)
+
+// applyJSONLogic is a reference of JSONLogic following jsonlogic.js, for the operations which are converted,
+// contains, startsWith, endsWith and toUpper are custom operations like add_operation
+func applyJSONLogic(rule, data interface{}) interface{} {
+	switch r := rule.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(r))
+		for i, elem := range r {
+			list[i] = applyJSONLogic(elem, data)
+		}
+		return list
+	case map[string]interface{}:
+		for op, args := range r {
+			list, ok := args.([]interface{})
+			if !ok {
+				list = []interface{}{args}
+			}
+			return applyOperation(op, list, data)
+		}
+	}
+	return rule
+}
+
+func applyOperation(op string, args []interface{}, data interface{}) interface{} {
+	switch op {
+	case `var`:
+		v := data
+		for _, key := range strings.Split(args[0].(string), `.`) {
+			switch x := v.(type) {
+			case map[string]interface{}:
+				v = x[key]
+			case []interface{}:
+				i, _ := strconv.Atoi(key)
+				v = x[i]
+			}
+		}
+		return v
+	case `and`, `or`:
+		var v interface{}
+		for _, arg := range args {
+			v = applyJSONLogic(arg, data)
+			if truthy(v) != (op == `and`) {
+				return v
+			}
+		}
+		return v
+	}
+	values := applyJSONLogic(args, data).([]interface{})
+	a := values[0]
+	switch op {
+	case `!`:
+		return !truthy(a)
+	case `==`:
+		return looseEqual(a, values[1])
+	case `===`:
+		return a == values[1]
+	case `!=`:
+		return !looseEqual(a, values[1])
+	case `!==`:
+		return a != values[1]
+	case `>`, `>=`, `<`, `<=`:
+		for i := 0; i+1 < len(values); i++ {
+			if !jsCompare(op, values[i], values[i+1]) {
+				return false
+			}
+		}
+		return true
+	case `+`, `*`:
+		result := toNumber(a)
+		for _, v := range values[1:] {
+			if op == `+` {
+				result += toNumber(v)
+			} else {
+				result *= toNumber(v)
+			}
+		}
+		return result
+	case `-`:
+		if len(values) == 1 {
+			return -toNumber(a)
+		}
+		return toNumber(a) - toNumber(values[1])
+	case `/`:
+		return toNumber(a) / toNumber(values[1])
+	case `%`:
+		return math.Mod(toNumber(a), toNumber(values[1]))
+	case `in`:
+		if list, ok := values[1].([]interface{}); ok {
+			for _, elem := range list {
+				if elem == a {
+					return true
+				}
+			}
+			return false
+		}
+		return strings.Contains(values[1].(string), a.(string))
+	case `cat`:
+		var b strings.Builder
+		for _, v := range values {
+			fmt.Fprint(&b, v)
+		}
+		return b.String()
+	case `contains`:
+		return strings.Contains(a.(string), values[1].(string))
+	case `startsWith`:
+		return strings.HasPrefix(a.(string), values[1].(string))
+	case `endsWith`:
+		return strings.HasSuffix(a.(string), values[1].(string))
+	case `toUpper`:
+		return strings.ToUpper(a.(string))
+	}
+	panic(`unknown operation: ` + op)
+}
+
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ``
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+func toNumber(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return f
+	}
+	return 0
+}
+
+func looseEqual(a, b interface{}) bool {
+	_, as := a.(string)
+	_, bs := b.(string)
+	if as == bs {
+		return a == b
+	}
+	return toNumber(a) == toNumber(b)
+}
+
+func jsCompare(op string, a, b interface{}) bool {
+	var c int
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		c = strings.Compare(as, bs)
+	} else {
+		x, y := toNumber(a), toNumber(b)
+		if math.IsNaN(x) || math.IsNaN(y) {
+			return false
+		}
+		switch {
+		case x < y:
+			c = -1
+		case x > y:
+			c = 1
+		}
+	}
+	switch op {
+	case `>`:
+		return c > 0
+	case `>=`:
+		return c >= 0
+	case `<`:
+		return c < 0
+	}
+	return c <= 0
+}
+
+var jsonLogicData = []string{
+	`{"age": 20, "car": "bmw", "a": 9, "b": 4, "name": "bob", "first": "ann", "last": "lee", "user": {"address": {"city": "x"}}, "tags": ["a", "b"], "brands": ["bmw"]}`,
+	`{"age": 18, "car": "byd", "a": 3, "b": 7, "name": "emma", "first": "bo", "last": "li", "user": {"address": {"city": "y"}}, "tags": ["b"], "brands": []}`,
+	`{"age": 35, "car": "audi", "a": -5, "b": 0.5, "name": "mike", "first": "ann", "last": "lee", "user": {"address": {"city": "x"}}, "tags": ["a"], "brands": ["bmw", "byd"]}`,
+}
+
+// checkJSONLogic checks that expr and rule give the same results with every data, rule is decoded from its JSON
+func checkJSONLogic(t *testing.T, expr string, rule interface{}) {
+	b, err := json.Marshal(rule)
+	require.Nil(t, err)
+	require.Nil(t, json.Unmarshal(b, &rule))
+	for _, data := range jsonLogicData {
+		var vars map[string]interface{}
+		require.Nil(t, json.Unmarshal([]byte(data), &vars))
+		got, err := Evaluate(expr, vars)
+		require.Nil(t, err, expr)
+		require.Equal(t, truthy(applyJSONLogic(rule, vars)), got, `%s with %s`, expr, data)
+	}
+}
+
+func TestFromJSONLogic(t *testing.T) {
+	rules := map[string]string{
+		`{"==": [{"var": "age"}, 18]}`: `$age = 18`,
+		`{"and": [{">": [{"var": "age"}, 18]}, {"in": [{"var": "car"}, ["bmw", "audi"]]}]}`:  `$age > 18 and $car in ['bmw', 'audi']`,
+		`{"or": [{"!": {"===": [{"var": "car"}, "bmw"]}}, {"<": [10, {"var": "age"}, 30]}]}`: `!($car = 'bmw') or 10 < $age and $age < 30`,
+		`{"<=": [{"+": [{"var": "a"}, {"*": [{"var": "b"}, 2]}, 1]}, 20]}`:                   `$a + $b * 2 + 1 <= 20`,
+		`{"in": [{"var": "name"}, "bob emma"]}`:                                              `contains('bob emma', $name)`,
+		`{"in": ["a", {"var": "tags"}]}`:                                                     `'a' in $tags`,
+		`{"==": [{"cat": [{"var": "first"}, " ", {"var": "last"}]}, "ann lee"]}`:             `concat($first, ' ', $last) = 'ann lee'`,
+		`{"!": {"in": [{"var": "car"}, ["byd"]]}}`:                                           `$car not in ['byd']`,
+		`{"startsWith": [{"var": "name"}, "b"]}`:                                             `startsWith($name, 'b')`,
+		`{">": [{"-": [{"var": "a"}, {"var": "b"}]}, {"%": [{"var": "a"}, 3]}]}`:             `$a - $b > $a % 3`,
+		`{"!=": [{"var": "user.address.city"}, "x"]}`:                                        `$user.address.city != 'x'`,
+		`{"or": [{"==": [{"var": "tags.0"}, "a"]}, {"<": [{"-": {"var": "a"}}, -2.5]}]}`:     `$tags[0] = 'a' or -$a < -2.5`,
+		`{">": [{"/": [{"var": "a"}, {"var": "b"}]}, 1.5]}`:                                  `$a * 1.0 / $b > 1.5`,
+		`{"==": [{"/": [{"var": "a"}, 2]}, 4.5]}`:                                            `$a / 2.0 = 4.5`,
+		`{"<": [{"/": [9, {"var": "b"}]}, 2.5]}`:                                             `9.0 / $b < 2.5`,
+	}
+	for rule, want := range rules {
+		expr, err := FromJSONLogic([]byte(rule))
+		require.Nil(t, err, rule)
+		require.Equal(t, want, expr, rule)
+		checkJSONLogic(t, expr, json.RawMessage(rule))
+	}
+
+	invalid := map[string]string{
+		`{"if": [true, 1, 2]}`:         `jsonlogic: operation: if is not supported`,
+		`{"var": ["a", 1]}`:            `jsonlogic: var with defaults is not supported`,
+		`{"var": "a..b"}`:              `jsonlogic: invalid var: a..b`,
+		`{"and": [true], "or": [1]}`:   `jsonlogic: operations must have one key, got 2`,
+		`{"!": [true, false]}`:         `jsonlogic: operation: ! takes 1 arguments, got 2`,
+		`{"+": [1]}`:                   `jsonlogic: operation: + takes more arguments, got 1`,
+		`{"==": [{"var": "a"}, null]}`: `jsonlogic: <nil> is not supported`,
+		`{"==": [1`:                    `jsonlogic: invalid rule`,
+		`{"==": [1, 2]} trailing`:      `jsonlogic: invalid rule`,
+		`{"==": [1, 2]} {}`:            `jsonlogic: invalid rule`,
+	}
+	for rule, msg := range invalid {
+		_, err := FromJSONLogic([]byte(rule))
+		require.NotNil(t, err, rule)
+		require.Contains(t, err.Error(), msg, rule)
+	}
+}
+
+func TestToJSONLogic(t *testing.T) {
+	exprs := []string{
+		`$age >= 18 and $car in ('bmw', 'audi') and $age < 30`,
+		`!($a > 1 or $b < 2)`,
+		`$car not in ['byd']`,
+		`contains($name, 'o') and startsWith($name, 'b') or endsWith($name, 'e')`,
+		`-$a + $b * 2 != 7`,
+		`$user.address.city = 'x' or $tags[0] = 'b'`,
+		`concat($first, $last) = 'annlee'`,
+		`toUpper($name) = 'BOB'`,
+		`'a' in $tags and $car not in $brands`,
+		`contains('bob emma', $name)`,
+		`$a * 1.0 / $b > 1.5 or $a / 2.0 < 1`,
+	}
+	for _, expr := range exprs {
+		rule, err := ToJSONLogic(expr)
+		require.Nil(t, err, expr)
+		checkJSONLogic(t, expr, rule)
+
+		// round trip
+		b, err := json.Marshal(rule)
+		require.Nil(t, err)
+		back, err := FromJSONLogic(b)
+		require.Nil(t, err, expr)
+		n, err := ParseAST(expr)
+		require.Nil(t, err)
+		require.Equal(t, ast.Format(n), back, expr)
+	}
+
+	rule, err := ToJSONLogic(`$car in {'bmw': 1, 'byd': 2}`)
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{`in`: []interface{}{map[string]interface{}{`var`: `car`}, []interface{}{`bmw`, `byd`}}}, rule)
+	checkJSONLogic(t, `$car in {'bmw': 1, 'byd': 2}`, rule)
+
+	program, err := Compile(`$age > 10 + 8`)
+	require.Nil(t, err)
+	rule, err = program.ToJSONLogic()
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{`>`: []interface{}{map[string]interface{}{`var`: `age`}, int64(18)}}, rule)
+
+	invalid := map[string]string{
+		`$a ** 2 > 1`: `jsonlogic: $a ** 2 has no equivalent`,
+		`x = 1`:       `jsonlogic: x has no equivalent`,
+	}
+	for expr, msg := range invalid {
+		_, err := ToJSONLogic(expr)
+		require.NotNil(t, err, expr)
+		require.Contains(t, err.Error(), msg, expr)
+	}
+}
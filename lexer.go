@@ -0,0 +1,224 @@
+package expr
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// tokens of Expr.g4, the lexer matches the longest token like ANTLR does
+type tokenKind uint8
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNot
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenLBracket
+	tokenRBracket
+	tokenLBrace
+	tokenRBrace
+	tokenColon
+	tokenOr
+	tokenAnd
+	tokenGT
+	tokenGTE
+	tokenLT
+	tokenLTE
+	tokenEQ
+	tokenNEQ
+	tokenIn
+	tokenNotIn
+	tokenPow
+	tokenMul
+	tokenMod
+	tokenDiv
+	tokenAdd
+	tokenSub
+	tokenInt
+	tokenFloat
+	tokenBool
+	tokenString
+	tokenVar
+	tokenIdent
+)
+
+type token struct {
+	kind tokenKind
+	span
+	text string
+}
+
+var (
+	punctuations = map[string]tokenKind{
+		`!`: tokenNot, `(`: tokenLParen, `)`: tokenRParen, `,`: tokenComma, `[`: tokenLBracket, `]`: tokenRBracket,
+		`{`: tokenLBrace, `}`: tokenRBrace, `:`: tokenColon, `>`: tokenGT, `>=`: tokenGTE, `<`: tokenLT,
+		`<=`: tokenLTE, `=`: tokenEQ, `!=`: tokenNEQ, `**`: tokenPow, `*`: tokenMul, `%`: tokenMod,
+		`/`: tokenDiv, `+`: tokenAdd, `-`: tokenSub,
+	}
+	keywords = map[string]tokenKind{
+		`or`: tokenOr, `and`: tokenAnd, `in`: tokenIn, `true`: tokenBool, `false`: tokenBool,
+	}
+	binaryOperators = map[tokenKind]operator{
+		tokenAnd: operatorAnd, tokenOr: operatorOr, tokenEQ: operatorEQ, tokenNEQ: operatorNEQ,
+		tokenGT: operatorGT, tokenGTE: operatorGTE, tokenLT: operatorLT, tokenLTE: operatorLTE,
+		tokenAdd: operatorAdd, tokenSub: operatorSub, tokenMul: operatorMul, tokenDiv: operatorDiv,
+		tokenMod: operatorMod, tokenPow: operatorPow,
+	}
+)
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// lex splits input into tokens ending with tokenEOF, characters which don't form tokens are skipped and reported
+func lex(input string) ([]token, ExprErrors) {
+	tokens := make([]token, 0, len(input)/2+1)
+	var errs ExprErrors
+	for i := 0; i < len(input); {
+		c := input[i]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			i++
+			continue
+		}
+		kind, end := tokenEOF, i
+		switch {
+		case isDigit(c) || c == '-' && i+1 < len(input) && isDigit(input[i+1]):
+			kind, end = lexNumber(input, i)
+		case c == '\'':
+			end = lexString(input, i)
+			if end < 0 {
+				// the rest is the unterminated string
+				errs = append(errs, lexError(input, span{start: i, end: len(input)}))
+				i = len(input)
+				continue
+			}
+			kind = tokenString
+		case c == '$':
+			if i+1 == len(input) || !isIdentStart(input[i+1]) {
+				errs = append(errs, lexError(input, span{start: i, end: i + 1}))
+				i++
+				continue
+			}
+			kind, end = tokenVar, lexVar(input, i+1)
+		case isIdentStart(c):
+			kind, end = tokenIdent, lexIdent(input, i)
+			if k, ok := keywords[input[i:end]]; ok {
+				kind = k
+			}
+			// `not in` is a token only with a single space
+			if input[i:end] == `not` && strings.HasPrefix(input[end:], ` in`) {
+				kind, end = tokenNotIn, end+3
+			}
+		default:
+			if i+1 < len(input) {
+				if k, ok := punctuations[input[i:i+2]]; ok {
+					kind, end = k, i+2
+					break
+				}
+			}
+			k, ok := punctuations[input[i:i+1]]
+			if !ok {
+				_, size := utf8.DecodeRuneInString(input[i:])
+				errs = append(errs, lexError(input, span{start: i, end: i + size}))
+				i += size
+				continue
+			}
+			kind, end = k, i+1
+		}
+		tokens = append(tokens, token{kind: kind, span: span{start: i, end: end}, text: input[i:end]})
+		i = end
+	}
+	return append(tokens, token{kind: tokenEOF, span: span{start: len(input), end: len(input)}, text: `<EOF>`}), errs
+}
+
+func lexError(input string, sp span) *ExprError {
+	return newExprError(input, sp, CategoryLexical, zerror.BadRequest.Errorf(`token recognition error at: '%s'`, input[sp.start:sp.end]))
+}
+
+// lineCol returns the line from 1 and the column from 0 of offset i, like ANTLR reports
+func lineCol(input string, i int) (int, int) {
+	line := 1 + strings.Count(input[:i], "\n")
+	return line, i - strings.LastIndex(input[:i], "\n") - 1
+}
+
+func lexNumber(input string, i int) (tokenKind, int) {
+	if input[i] == '-' {
+		i++
+	}
+	for i < len(input) && isDigit(input[i]) {
+		i++
+	}
+	if i+1 < len(input) && input[i] == '.' && isDigit(input[i+1]) {
+		i++
+		for i < len(input) && isDigit(input[i]) {
+			i++
+		}
+		return tokenFloat, i
+	}
+	return tokenInt, i
+}
+
+func lexIdent(input string, i int) int {
+	for i < len(input) && isIdentPart(input[i]) {
+		i++
+	}
+	return i
+}
+
+// lexVar matches fields and indexes after the name, trailing ones which are incomplete are left out
+func lexVar(input string, i int) int {
+	i = lexIdent(input, i)
+	for i < len(input) {
+		switch {
+		case input[i] == '.' && i+1 < len(input) && isIdentStart(input[i+1]):
+			i = lexIdent(input, i+1)
+		case input[i] == '[' && i+1 < len(input) && isDigit(input[i+1]):
+			j := i + 1
+			for j < len(input) && isDigit(input[j]) {
+				j++
+			}
+			if j == len(input) || input[j] != ']' {
+				return i
+			}
+			i = j + 1
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// lexString returns the end of the string starting at i, -1 if it's not terminated.
+// \' and \\ are escapes, but like the non-greedy ANTLR rule,
+// an unterminated string ends at the last quote which was taken as escaped
+func lexString(input string, i int) int {
+	last := -1
+	// odd is true after an odd number of backslashes
+	odd := false
+	for i++; i < len(input); i++ {
+		switch input[i] {
+		case '\'':
+			if !odd {
+				return i + 1
+			}
+			last = i + 1
+			odd = false
+		case '\\':
+			odd = !odd
+		default:
+			odd = false
+		}
+	}
+	return last
+}
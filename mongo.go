@@ -0,0 +1,174 @@
+package expr
+
+import (
+	"regexp"
+)
+
+var mongoOperators = map[operator]string{
+	operatorEQ: `$eq`, operatorNEQ: `$ne`, operatorGT: `$gt`, operatorGTE: `$gte`, operatorLT: `$lt`, operatorLTE: `$lte`,
+	operatorAdd: `$add`, operatorSub: `$subtract`, operatorMul: `$multiply`, operatorDiv: `$divide`,
+	operatorMod: `$mod`, operatorPow: `$pow`,
+}
+
+// ToMongo translates expr into a filter document of MongoDB, like {"age": {"$gt": 18}}.
+// comparisons of variables and literals are query operators, other comparisons, like `$a > $b + 1`, are in $expr,
+// `in` with lists and maps is $in, startsWith, endsWith and contains of string literals are $regex.
+// MongoDB differs on missing fields, which fail Evaluate, $ne and $nin match them,
+// and $divide doesn't truncate division of integers
+func ToMongo(expr string, opts ...QueryOption) (map[string]interface{}, error) {
+	program, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return program.ToMongo(opts...)
+}
+
+// ToMongo translates the program like the package level ToMongo, constants are folded before translating
+func (p *Program) ToMongo(opts ...QueryOption) (map[string]interface{}, error) {
+	w := &mongoWriter{queryOptions: newQueryOptions(opts)}
+	return w.filter(p.root)
+}
+
+type mongoWriter struct {
+	queryOptions
+}
+
+func (w *mongoWriter) filter(n node) (map[string]interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		if n.val.kind == kindBool {
+			if n.val.b {
+				return map[string]interface{}{}, nil
+			}
+			return map[string]interface{}{`$expr`: false}, nil
+		}
+	case *variableNode:
+		field, err := w.field(`mongo`, n, true)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{field: true}, nil
+	case *unaryNode:
+		if n.op == operatorNot {
+			x, err := w.filter(n.x)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{`$nor`: []interface{}{x}}, nil
+		}
+	case *binaryNode:
+		return w.binary(n)
+	case *inNode:
+		v, ok := n.x.(*variableNode)
+		if !ok {
+			return nil, errTranslate(`mongo`, n, `in is only supported with variables on the left`)
+		}
+		field, err := w.field(`mongo`, v, true)
+		if err != nil {
+			return nil, err
+		}
+		values, err := inValues(`mongo`, n)
+		if err != nil {
+			return nil, err
+		}
+		op := `$in`
+		if n.not {
+			op = `$nin`
+		}
+		return map[string]interface{}{field: map[string]interface{}{op: values}}, nil
+	case *callNode:
+		v, s, err := stringMatch(`mongo`, n)
+		if err != nil {
+			return nil, err
+		}
+		field, err := w.field(`mongo`, v, true)
+		if err != nil {
+			return nil, err
+		}
+		pattern := regexp.QuoteMeta(s)
+		switch n.name {
+		case `startsWith`:
+			pattern = `^` + pattern
+		case `endsWith`:
+			pattern += `$`
+		}
+		return map[string]interface{}{field: map[string]interface{}{`$regex`: pattern}}, nil
+	}
+	return nil, errTranslate(`mongo`, n, `it's not a condition`)
+}
+
+func (w *mongoWriter) binary(n *binaryNode) (map[string]interface{}, error) {
+	if n.op == operatorAnd || n.op == operatorOr {
+		operands := flatten(n)
+		filters := make([]interface{}, len(operands))
+		for i, x := range operands {
+			filter, err := w.filter(x)
+			if err != nil {
+				return nil, err
+			}
+			filters[i] = filter
+		}
+		if n.op == operatorAnd {
+			return map[string]interface{}{`$and`: filters}, nil
+		}
+		return map[string]interface{}{`$or`: filters}, nil
+	}
+	if cond, ok := boolCondition(n); ok {
+		return w.filter(cond)
+	}
+	if n.bools {
+		return nil, errTranslate(`mongo`, n, `conditions can't be compared`)
+	}
+	if v, op, lit, ok := fieldComparison(n); ok {
+		field, err := w.field(`mongo`, v, true)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{field: map[string]interface{}{mongoOperators[op]: lit}}, nil
+	}
+	e, err := w.expression(n)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{`$expr`: e}, nil
+}
+
+// expression translates n into an aggregation expression of $expr
+func (w *mongoWriter) expression(n node) (interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		// strings are left out, they would be field paths if they start with $, and math can't be done with them
+		if n.val.kind == kindInt || n.val.kind == kindFloat {
+			return n.val.interfaceValue(), nil
+		}
+	case *variableNode:
+		field, err := w.field(`mongo`, n, true)
+		if err != nil {
+			return nil, err
+		}
+		return `$` + field, nil
+	case *unaryNode:
+		if n.op == operatorNeg {
+			x, err := w.expression(n.x)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{`$subtract`: []interface{}{0, x}}, nil
+		}
+	case *binaryNode:
+		op, ok := mongoOperators[n.op]
+		if !ok || n.bools {
+			break
+		}
+		x, err := w.expression(n.x)
+		if err != nil {
+			return nil, err
+		}
+		y, err := w.expression(n.y)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{op: []interface{}{x, y}}, nil
+	}
+	return nil, errTranslate(`mongo`, n, `it has no equivalent of aggregation expressions`)
+}
@@ -0,0 +1,32 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToMongo(t *testing.T) {
+	queries := map[string]interface{}{}
+	for _, expr := range append(queryExprs, `$a > $b * 2`, `-$a + 1 < $b`, `$a != $b % $c`) {
+		q, err := ToMongo(expr)
+		require.Nil(t, err, expr)
+		queries[expr] = q
+	}
+	checkGolden(t, `mongo.json`, queries)
+
+	cases := map[string]string{
+		`'a' in $tags`:        `mongo: can not translate 'a' in $tags, in is only supported with variables on the left`,
+		`$a in $tags`:         `mongo: can not translate $a in $tags, in is only supported with lists and maps`,
+		`$a in [1, $b]`:       `mongo: can not translate $b, elements of in must be bools, numbers or strings`,
+		`length($a) > 1`:      `mongo: can not translate length($a), it has no equivalent of aggregation expressions`,
+		`startsWith($a, $b)`:  `mongo: can not translate startsWith($a, $b), the pattern must be a string literal`,
+		`toLower($a) = 'a'`:   `mongo: can not translate toLower($a), it has no equivalent of aggregation expressions`,
+		`($a > 1) = ($b > 1)`: `mongo: can not translate ($a > 1) = ($b > 1), conditions can't be compared`,
+	}
+	for input, msg := range cases {
+		_, err := ToMongo(input)
+		require.NotNil(t, err, input)
+		require.Contains(t, err.Error(), msg, input)
+	}
+}
@@ -0,0 +1,166 @@
+package expr
+
+// nodes are the lowered form of the parse tree, codegen works on them
+
+type operator uint8
+
+const (
+	operatorNot operator = iota + 1
+	operatorNeg
+	operatorAnd
+	operatorOr
+	operatorEQ
+	operatorNEQ
+	operatorGT
+	operatorGTE
+	operatorLT
+	operatorLTE
+	operatorAdd
+	operatorSub
+	operatorMul
+	operatorDiv
+	operatorMod
+	operatorPow
+)
+
+var operatorTexts = [...]string{
+	operatorNot: `!`,
+	operatorNeg: `-`,
+	operatorAnd: `and`,
+	operatorOr:  `or`,
+	operatorEQ:  `=`,
+	operatorNEQ: `!=`,
+	operatorGT:  `>`,
+	operatorGTE: `>=`,
+	operatorLT:  `<`,
+	operatorLTE: `<=`,
+	operatorAdd: `+`,
+	operatorSub: `-`,
+	operatorMul: `*`,
+	operatorDiv: `/`,
+	operatorMod: `%`,
+	operatorPow: `**`,
+}
+
+func (o operator) String() string {
+	return operatorTexts[o]
+}
+
+func (o operator) isCompare() bool {
+	return o >= operatorEQ && o <= operatorLTE
+}
+
+func (o operator) isMath() bool {
+	return o >= operatorAdd && o <= operatorPow
+}
+
+// isNumber reports if n results in a number even though its kind may only be known at runtime
+func isNumber(n node) bool {
+	switch n := n.(type) {
+	case *unaryNode:
+		return n.op == operatorNeg
+	case *binaryNode:
+		return n.op.isMath()
+	}
+	return false
+}
+
+// span is the byte offsets [start, end) of a node in the source
+type span struct {
+	start, end int
+}
+
+func (s span) pos() span { return s }
+
+type node interface {
+	pos() span
+}
+
+type literalNode struct {
+	span
+	val value
+}
+
+type variableNode struct {
+	span
+	name string
+	// fields and indexes after name
+	path []pathStep
+	// the variable must be bool, it's set when `true and $x` is simplified to $x
+	isBool bool
+}
+
+type identifierNode struct {
+	span
+	name string
+}
+
+type unaryNode struct {
+	span
+	op operator
+	x  node
+}
+
+type binaryNode struct {
+	span
+	op   operator
+	x, y node
+	// = and != compare bool expressions, like `($a > 1) = true`, operands are checked as bools
+	bools bool
+}
+
+// inNode tests if x is in y, a list or a map
+type inNode struct {
+	span
+	not bool
+	x   node
+	y   node
+	// y is a bracketed list of strings or numbers, like ('a', 'b'), x is checked against its type
+	literals bool
+}
+
+type listNode struct {
+	span
+	elems []node
+}
+
+type mapNode struct {
+	span
+	keys   []string
+	values []node
+}
+
+type callNode struct {
+	span
+	name string
+	args []node
+}
+
+// inspect calls fn for n and its descendants in depth-first order, descendants are skipped if fn returns false
+func inspect(n node, fn func(node) bool) {
+	if !fn(n) {
+		return
+	}
+	switch n := n.(type) {
+	case *unaryNode:
+		inspect(n.x, fn)
+	case *binaryNode:
+		inspect(n.x, fn)
+		inspect(n.y, fn)
+	case *inNode:
+		inspect(n.x, fn)
+		inspect(n.y, fn)
+	case *listNode:
+		for _, elem := range n.elems {
+			inspect(elem, fn)
+		}
+	case *mapNode:
+		for _, v := range n.values {
+			inspect(v, fn)
+		}
+	case *callNode:
+		for _, arg := range n.args {
+			inspect(arg, fn)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package expr
+
+import (
+	"context"
+	"reflect"
+)
+
+// optimizer folds constant nodes, including calls of pure functions, and simplifies logic operations,
+// PartialEval binds known variables by it too
+type optimizer struct {
+	known map[string]interface{}
+	// known variables kept in the residual program, like structs passed to interface{} parameters
+	bound   map[string]interface{}
+	getFunc func(name string) (*function, error)
+}
+
+func (o *optimizer) optimize(n node) node {
+	switch n := n.(type) {
+	case *variableNode:
+		return o.variable(n, false)
+	case *identifierNode:
+		if i, ok := o.known[n.name]; ok {
+			if lit, ok := literalOf(n.span, n.name, i); ok {
+				return lit
+			}
+		}
+		return n
+	case *unaryNode:
+		x := o.optimize(n.x)
+		// !!x is x
+		if not, ok := x.(*unaryNode); ok && n.op == operatorNot && not.op == operatorNot {
+			return mustBool(not.x)
+		}
+		return o.fold(&unaryNode{span: n.span, op: n.op, x: x})
+	case *binaryNode:
+		x, y := o.optimize(n.x), o.optimize(n.y)
+		if n.op == operatorAnd || n.op == operatorOr {
+			return o.logic(n, x, y)
+		}
+		return o.fold(&binaryNode{span: n.span, op: n.op, x: x, y: y, bools: n.bools})
+	case *inNode:
+		return o.fold(&inNode{span: n.span, not: n.not, x: o.optimize(n.x), y: o.optimize(n.y), literals: n.literals})
+	case *listNode:
+		list := &listNode{span: n.span, elems: make([]node, len(n.elems))}
+		for i, elem := range n.elems {
+			list.elems[i] = o.optimize(elem)
+		}
+		return list
+	case *mapNode:
+		m := &mapNode{span: n.span, keys: n.keys, values: make([]node, len(n.values))}
+		for i, v := range n.values {
+			m.values[i] = o.optimize(v)
+		}
+		return m
+	case *callNode:
+		fn, err := o.getFunc(n.name)
+		if err != nil {
+			return n
+		}
+		call := &callNode{span: n.span, name: n.name, args: make([]node, len(n.args))}
+		for i, arg := range n.args {
+			// interface{} parameters take raw variables
+			if v, ok := arg.(*variableNode); ok && fn.in(i).Kind() == reflect.Interface {
+				call.args[i] = o.variable(v, true)
+				continue
+			}
+			call.args[i] = o.optimize(arg)
+		}
+		if !fn.pure {
+			return call
+		}
+		return o.fold(call)
+	}
+	return n
+}
+
+// variable replaces known variables with literals, or binds them if they can't be literals
+func (o *optimizer) variable(n *variableNode, raw bool) node {
+	i, ok := o.known[n.name]
+	if !ok {
+		return n
+	}
+	if !raw {
+		if x, err := walkPath(n.name, i, n.path); err == nil {
+			if lit, ok := literalOf(n.span, n.name, x); ok {
+				return lit
+			}
+		}
+	}
+	o.bound[n.name] = i
+	return n
+}
+
+func literalOf(sp span, name string, i interface{}) (*literalNode, bool) {
+	v, err := valueOf(name, i)
+	if err != nil {
+		return nil, false
+	}
+	if _, ok := formatValue(v); !ok {
+		return nil, false
+	}
+	return &literalNode{span: sp, val: v}, true
+}
+
+// logic simplifies `true and x` to x and `false and x` to false, and `or` likewise.
+// `x and false` is false only if x is pure, or calls of x would be dropped,
+// and x results in bool statically, or a missing or non-bool variable would no longer fail
+func (o *optimizer) logic(n *binaryNode, x, y node) node {
+	isAnd := n.op == operatorAnd
+	if b, ok := boolLiteral(x); ok {
+		if b != isAnd {
+			return x
+		}
+		return mustBool(y)
+	}
+	if b, ok := boolLiteral(y); ok {
+		if b == isAnd {
+			return mustBool(x)
+		}
+		if o.pure(x) && o.isBool(x) {
+			return y
+		}
+	}
+	return &binaryNode{span: n.span, op: n.op, x: x, y: y}
+}
+
+// mustBool keeps the runtime check of bool variables whose operator is simplified away
+func mustBool(n node) node {
+	if v, ok := n.(*variableNode); ok && !v.isBool {
+		c := *v
+		c.isBool = true
+		return &c
+	}
+	return n
+}
+
+func boolLiteral(n node) (bool, bool) {
+	if lit, ok := n.(*literalNode); ok && lit.val.kind == kindBool {
+		return lit.val.b, true
+	}
+	return false, false
+}
+
+// isBool reports if n results in bool statically, variables are only known at runtime
+func (o *optimizer) isBool(n node) bool {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.val.kind == kindBool
+	case *unaryNode:
+		return n.op == operatorNot
+	case *binaryNode:
+		return n.op == operatorAnd || n.op == operatorOr || n.op.isCompare()
+	case *inNode:
+		return true
+	case *callNode:
+		fn, err := o.getFunc(n.name)
+		return err == nil && fn.returnType.Kind() == reflect.Bool
+	}
+	return false
+}
+
+// pure reports if n calls only pure functions
+func (o *optimizer) pure(n node) bool {
+	pure := true
+	inspect(n, func(n node) bool {
+		if call, ok := n.(*callNode); ok {
+			if fn, err := o.getFunc(call.name); err != nil || !fn.pure {
+				pure = false
+			}
+		}
+		return pure
+	})
+	return pure
+}
+
+// fold evaluates n if its operands are constants, n is kept if it fails, so the error is reported by evaluation
+func (o *optimizer) fold(n node) node {
+	var operands []node
+	switch n := n.(type) {
+	case *unaryNode:
+		operands = []node{n.x}
+	case *binaryNode:
+		operands = []node{n.x, n.y}
+	case *inNode:
+		operands = []node{n.x, n.y}
+	case *callNode:
+		operands = n.args
+	}
+	for _, operand := range operands {
+		if _, ok := constValue(operand); !ok {
+			return n
+		}
+	}
+	v, err := evalNode(n, o.getFunc)
+	if err != nil {
+		return n
+	}
+	if _, ok := formatValue(v); !ok {
+		return n
+	}
+	return &literalNode{span: n.pos(), val: v}
+}
+
+// evalNode evaluates n without variables
+func evalNode(n node, getFunc func(name string) (*function, error)) (value, error) {
+	code, _, err := compileNode(n, getFunc)
+	if err != nil {
+		return value{}, err
+	}
+	m := vmPool.Get().(*vm)
+	defer m.release()
+	m.ctx = context.Background()
+	return m.run(code)
+}
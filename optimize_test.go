@@ -0,0 +1,118 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptimize(t *testing.T) {
+	parser := NewParser()
+	require.Nil(t, parser.RegisterFunc(`impure`, func() bool { return true }))
+	require.Nil(t, parser.RegisterFunc(`square`, func(i int64) int64 { return i * i }, Pure()))
+	require.Nil(t, parser.RegisterFunc(`cube`, func(i int64) int64 { return i * i * i }))
+	cases := map[string]string{
+		`(3 + 2) * 2.0 = 10 and startsWith($car, 'b')`: `startsWith($car, 'b')`,
+		`!!$a`:                        `$a`,
+		`!!!($a > 1)`:                 `!($a > 1)`,
+		`$a > 1 or true`:              `true`,
+		`$a > 1 and false or $b`:      `$b`,
+		`impure() or true`:            `impure() or true`,
+		`$a or true`:                  `$a or true`,
+		`$a and false`:                `$a and false`,
+		`startsWith($a, 'x') or true`: `true`,
+		`false and impure()`:          `false`,
+		`true or impure()`:            `true`,
+		`toUpper('a') = $b`:           `'A' = $b`,
+		`now() = $b`:                  `now() = $b`,
+		`square(3) > $a`:              `9 > $a`,
+		`cube(3) > $a`:                `cube(3) > $a`,
+		`square(1 / 0) > $a`:          `square(1 / 0) > $a`,
+		`$a in [1 + 1, 'a'] and $b`:   `$a in [2, 'a'] and $b`,
+		`length(['a', 'b']) + $a > 1`: `2 + $a > 1`,
+	}
+	for input, expect := range cases {
+		program, err := parser.CompileValue(input)
+		require.Nil(t, err, input)
+		require.Equal(t, expect, formatNode(program.root), input)
+	}
+
+	// the runtime check of bool variables is kept
+	for _, input := range []string{`!!$a`, `true and $a`, `$a or false`} {
+		program, err := parser.Compile(input)
+		require.Nil(t, err, input)
+		_, err = program.EvalValue(map[string]interface{}{`a`: 1})
+		require.NotNil(t, err, input)
+		result, err := program.Eval(map[string]interface{}{`a`: true})
+		require.Nil(t, err, input)
+		require.True(t, result, input)
+	}
+
+	// variables which are missing or not bool fail though the other operand decides the result
+	for input, expect := range map[string]bool{`$x or true`: true, `$x and false`: false} {
+		_, err := Evaluate(input, map[string]interface{}{})
+		require.Contains(t, err.Error(), `var: x not found`, input)
+		_, err = Evaluate(input, map[string]interface{}{`x`: 5})
+		require.NotNil(t, err, input)
+		result, err := Evaluate(input, map[string]interface{}{`x`: false})
+		require.Nil(t, err, input)
+		require.Equal(t, expect, result, input)
+	}
+}
+
+func TestSharedSubExpressions(t *testing.T) {
+	parser := NewParser()
+	called := 0
+	double := func(i int64) int64 {
+		called++
+		return i * 2
+	}
+	require.Nil(t, parser.RegisterFunc(`double`, double, Pure()))
+	require.Nil(t, parser.RegisterFunc(`count`, func(i int64) int64 {
+		called++
+		return i
+	}))
+	cases := []struct {
+		expr   string
+		vars   map[string]interface{}
+		result bool
+		called int
+	}{
+		{`double($a) > 1 and double($a) < 10 and double($a) != 4`, map[string]interface{}{`a`: 3}, true, 1},
+		{`$b and double($a) > 1 or double($a) < 0`, map[string]interface{}{`a`: 3, `b`: false}, false, 1},
+		{`$b and double($a) > 1 or double($a) < 0`, map[string]interface{}{`a`: 3, `b`: true}, true, 1},
+		{`double(double($a)) = 12 and double($a) = 6`, map[string]interface{}{`a`: 3}, true, 2},
+		{`count($a) = 3 and count($a) = 3`, map[string]interface{}{`a`: 3}, true, 2},
+	}
+	for _, c := range cases {
+		program, err := parser.Compile(c.expr)
+		require.Nil(t, err, c.expr)
+		for i := 0; i < 2; i++ {
+			called = 0
+			result, err := program.Eval(c.vars)
+			require.Nil(t, err, c.expr)
+			require.Equal(t, c.result, result, c.expr)
+			require.Equal(t, c.called, called, c.expr)
+		}
+	}
+
+	program, err := parser.Compile(`double($a) > 1 and double($a) < 10`)
+	require.Nil(t, err)
+	require.Equal(t, 1, program.code.nslots)
+	expect := `; double($a) > 1 and double($a) < 10
+0000 slot.load     r0 = slot0 -> 0004
+0001 var           r0 = $a
+0002 call          r0 = double(r0)
+0003 slot.store    slot0 = r0
+0004 const         r1 = 1
+0005 cmp.int       r0 = r0 > r1
+0006 jump.false    r0 -> 0013
+0007 slot.load     r0 = slot0 -> 0011
+0008 var           r0 = $a
+0009 call          r0 = double(r0)
+0010 slot.store    slot0 = r0
+0011 const         r1 = 10
+0012 cmp.int       r0 = r0 < r1
+`
+	require.Equal(t, expect, program.Disassemble())
+}
@@ -0,0 +1,43 @@
+package expr
+
+// FuncOption configures a registered function
+type FuncOption func(*function)
+
+// WithCost sets the cost of one call to the function, it's added to the cost of the call node
+func WithCost(cost int64) FuncOption {
+	return func(f *function) {
+		f.cost = cost
+	}
+}
+
+// Pure marks the function as pure: its result depends only on its args and it has no side effects,
+// so calls with constant args are evaluated once when compiled, and identical calls are evaluated once per evaluation
+func Pure() FuncOption {
+	return func(f *function) {
+		f.pure = true
+	}
+}
+
+// WithDefaults sets defaults of the trailing parameters before the variadic one,
+// so calls can omit them, like WithDefaults(10) for func(s string, limit int64) bool
+func WithDefaults(values ...interface{}) FuncOption {
+	return func(f *function) {
+		f.rawDefaults = values
+	}
+}
+
+type evalOptions struct {
+	budget int64
+}
+
+// EvalOption configures one evaluation
+type EvalOption func(*evalOptions)
+
+// WithCostBudget aborts evaluation with BudgetExceeded once its cost exceeds budget.
+// every evaluated node costs 1, a function call costs 1 plus the function's cost,
+// budget <= 0 means no limit
+func WithCostBudget(budget int64) EvalOption {
+	return func(o *evalOptions) {
+		o.budget = budget
+	}
+}
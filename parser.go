@@ -1,118 +1,115 @@
 package expr
 
 import (
-	"sync"
-
-	"github.com/EchoUtopia/expr/parser"
-	"github.com/EchoUtopia/zerror"
-	"github.com/antlr/antlr4/runtime/Go/antlr"
+	"github.com/EchoUtopia/expr/ast"
 )
 
-func init() {
-
-	for k, v := range builtinFuncs {
-		if err := RegisterFunc(k, v); err != nil {
-			panic(err)
-		}
+// Parse can check some errors before evaluate stage
+func Parse(input string) (tree *Tree, err error) {
+	parser := NewParser()
+	tree, err = parser.ParseWithCache(input)
+	if err != nil {
+		return nil, err
 	}
+	return tree, nil
 }
 
-type parsedPools struct {
-	sync.Mutex
-	trees map[string]antlr.Tree
+// RegisterFunc registers fn in the default Env used by package level functions,
+// its first parameter can be context.Context
+func RegisterFunc(name string, fn interface{}, opts ...FuncOption) error {
+	return defaultEnv.RegisterFunc(name, fn, opts...)
 }
 
-var pools = &parsedPools{trees: map[string]antlr.Tree{}}
-
-type funcPools struct {
-	sync.RWMutex
-	funcs map[string]*function
+func (l *listenerForParse) Parse(input string) (tree *Tree, err error) {
+	tree, err = parseSyntax(input)
+	if err != nil {
+		return tree, err
+	}
+	if err = l.check(tree); err != nil {
+		return tree, err
+	}
+	return tree, l.expectBool(tree)
 }
 
-var globalFuncs = funcPools{funcs: map[string]*function{}}
-
-func (fp *funcPools) get(name string) (*function, bool) {
-	fp.RLock()
-	defer fp.RUnlock()
-	fn, ok := globalFuncs.funcs[name]
-	return fn, ok
+// Tree is an expression parsed by Parse, it's immutable so it can be cached and shared
+type Tree struct {
+	input string
+	root  node
 }
 
-func (fp *funcPools) set(name string, fn *function) {
-	fp.Lock()
-	defer fp.Unlock()
-	globalFuncs.funcs[name] = fn
+// AST returns the syntax tree of t, see ParseAST
+func (t *Tree) AST() ast.Node {
+	return toAST(t.root)
 }
 
-// Parse can check some errors before evaluate stage
-func Parse(input string) (tree antlr.Tree, err error) {
-	parser := NewParser()
-	//tree, err := parser.Parse(expr)
-	tree, err = parser.ParseWithCache(input)
+// parseSyntax builds the tree of input, it doesn't depend on any parser so trees can be shared
+func parseSyntax(input string) (*Tree, error) {
+	root, err := parseNodes(input)
 	if err != nil {
 		return nil, err
 	}
-	return tree, nil
+	return &Tree{input: input, root: root}, nil
 }
 
-func RegisterFunc(name string, fn interface{}) error {
-
-	_, ok := globalFuncs.get(name)
-	if ok {
-		return zerror.AlreadyExists.WithMsg(name)
-	}
-	returnType, fnVal, err := checkFunction(name, fn)
-	if err != nil {
-		return err
+// check runs the checks of Parse on a parsed tree, functions may differ from the parser which parsed the tree.
+// all errors found are returned as ExprErrors
+func (l *listenerForParse) check(tree *Tree) error {
+	l.reset()
+	c := &checker{l: l, input: tree.input}
+	v := c.check(tree.root, false)
+	if c.errs != nil {
+		return c.errs
 	}
-	fnType := fnVal.Type()
-	f := &function{
-		fn:         fnVal,
-		returnType: returnType,
-		argsNumber: fnType.NumIn(),
-		isVariadic: fnType.IsVariadic(),
-		name:       name,
+	switch {
+	case isNumberArg(v):
+		l.resultNumber = true
+	case v.Type() != reflectArgType:
+		l.resultType = v.Type()
 	}
-	globalFuncs.set(name, f)
 	return nil
 }
 
-func (l *listenerForParse) Parse(input string) (tree antlr.Tree, err error) {
-	defer func() {
-		if recovered := recover(); recovered != nil {
-			err = zerror.Internal.Errorf(`panic: %v`, recovered)
-		}
-	}()
-	l.reset()
-	is := antlr.NewInputStream(input)
-
-	// Create the Lexer
-	lexer := parser.NewExprLexer(is)
-	lexer.RemoveErrorListeners()
-	lexer.AddErrorListener(l.ErrorListener)
-	stream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
-	// Create the Parser
-	p := parser.NewExprParser(stream)
-	p.RemoveErrorListeners()
-	p.AddErrorListener(l.ErrorListener)
-	tree = p.Start()
-	l.walker.Walk(l, tree)
-	err = l.err
-	return
+// ParseWithCache is like Parse, but trees are shared through the cache of l.
+// concurrent misses of the same input are parsed once, outside of any lock
+func (l *listenerForParse) ParseWithCache(input string) (*Tree, error) {
+	tree, err := l.ParseValueWithCache(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.expectBool(tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
 }
 
-// for performance improvement
-func (l *listenerForParse) ParseWithCache(input string) (antlr.Tree, error) {
-	pools.Lock()
-	defer pools.Unlock()
-	tree, ok := pools.trees[input]
-	if ok {
+// ParseValueWithCache is like ParseWithCache, but the expression can result in any type, see ResultType
+func (l *listenerForParse) ParseValueWithCache(input string) (*Tree, error) {
+	if l.cache == nil {
+		tree, err := parseSyntax(input)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.check(tree); err != nil {
+			return nil, err
+		}
 		return tree, nil
 	}
-	tree, err := l.Parse(input)
-	if err != nil {
+	tree, ok := l.cache.Get(input)
+	if !ok {
+		var err error
+		tree, err = parses.do(input, parseSyntax)
+		if err != nil {
+			return nil, err
+		}
+		l.cache.Set(input, tree)
+	}
+	if err := l.check(tree); err != nil {
 		return nil, err
 	}
-	pools.trees[input] = tree
 	return tree, nil
 }
+
+// SetCache sets the cache used by ParseWithCache, nil disables caching
+func (l *listenerForParse) SetCache(c Cache) {
+	l.cache = c
+}
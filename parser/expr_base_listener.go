@@ -93,6 +93,12 @@ func (s *BaseExprListener) EnterBoolBracket(ctx *BoolBracketContext) {}
 // ExitBoolBracket is called when production BoolBracket is exited.
 func (s *BaseExprListener) ExitBoolBracket(ctx *BoolBracketContext) {}
 
+// EnterMapExpression is called when production MapExpression is entered.
+func (s *BaseExprListener) EnterMapExpression(ctx *MapExpressionContext) {}
+
+// ExitMapExpression is called when production MapExpression is exited.
+func (s *BaseExprListener) ExitMapExpression(ctx *MapExpressionContext) {}
+
 // EnterBracket is called when production Bracket is entered.
 func (s *BaseExprListener) EnterBracket(ctx *BracketContext) {}
 
@@ -123,6 +129,18 @@ func (s *BaseExprListener) EnterAddSub(ctx *AddSubContext) {}
 // ExitAddSub is called when production AddSub is exited.
 func (s *BaseExprListener) ExitAddSub(ctx *AddSubContext) {}
 
+// EnterListExpression is called when production ListExpression is entered.
+func (s *BaseExprListener) EnterListExpression(ctx *ListExpressionContext) {}
+
+// ExitListExpression is called when production ListExpression is exited.
+func (s *BaseExprListener) ExitListExpression(ctx *ListExpressionContext) {}
+
+// EnterPow is called when production Pow is entered.
+func (s *BaseExprListener) EnterPow(ctx *PowContext) {}
+
+// ExitPow is called when production Pow is exited.
+func (s *BaseExprListener) ExitPow(ctx *PowContext) {}
+
 // EnterExpressionFunction is called when production ExpressionFunction is entered.
 func (s *BaseExprListener) EnterExpressionFunction(ctx *ExpressionFunctionContext) {}
 
@@ -165,6 +183,30 @@ func (s *BaseExprListener) EnterNumberList(ctx *NumberListContext) {}
 // ExitNumberList is called when production numberList is exited.
 func (s *BaseExprListener) ExitNumberList(ctx *NumberListContext) {}
 
+// EnterListLiteral is called when production listLiteral is entered.
+func (s *BaseExprListener) EnterListLiteral(ctx *ListLiteralContext) {}
+
+// ExitListLiteral is called when production listLiteral is exited.
+func (s *BaseExprListener) ExitListLiteral(ctx *ListLiteralContext) {}
+
+// EnterMapLiteral is called when production mapLiteral is entered.
+func (s *BaseExprListener) EnterMapLiteral(ctx *MapLiteralContext) {}
+
+// ExitMapLiteral is called when production mapLiteral is exited.
+func (s *BaseExprListener) ExitMapLiteral(ctx *MapLiteralContext) {}
+
+// EnterPair is called when production pair is entered.
+func (s *BaseExprListener) EnterPair(ctx *PairContext) {}
+
+// ExitPair is called when production pair is exited.
+func (s *BaseExprListener) ExitPair(ctx *PairContext) {}
+
+// EnterElement is called when production element is entered.
+func (s *BaseExprListener) EnterElement(ctx *ElementContext) {}
+
+// ExitElement is called when production element is exited.
+func (s *BaseExprListener) ExitElement(ctx *ElementContext) {}
+
 // EnterFunction is called when production function is entered.
 func (s *BaseExprListener) EnterFunction(ctx *FunctionContext) {}
 
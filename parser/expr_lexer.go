@@ -14,90 +14,108 @@ var _ = fmt.Printf
 var _ = unicode.IsLetter
 
 var serializedLexerAtn = []uint16{
-	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 2, 28, 189,
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 2, 34, 228,
 	8, 1, 4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7,
 	9, 7, 4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12,
 	4, 13, 9, 13, 4, 14, 9, 14, 4, 15, 9, 15, 4, 16, 9, 16, 4, 17, 9, 17, 4,
 	18, 9, 18, 4, 19, 9, 19, 4, 20, 9, 20, 4, 21, 9, 21, 4, 22, 9, 22, 4, 23,
 	9, 23, 4, 24, 9, 24, 4, 25, 9, 25, 4, 26, 9, 26, 4, 27, 9, 27, 4, 28, 9,
-	28, 4, 29, 9, 29, 4, 30, 9, 30, 4, 31, 9, 31, 3, 2, 3, 2, 3, 3, 3, 3, 3,
-	4, 3, 4, 3, 5, 3, 5, 3, 6, 3, 6, 3, 6, 3, 7, 3, 7, 3, 7, 3, 7, 3, 8, 3,
-	8, 3, 9, 3, 9, 3, 9, 3, 10, 3, 10, 3, 11, 3, 11, 3, 11, 3, 12, 3, 12, 3,
-	13, 3, 13, 3, 13, 3, 14, 3, 14, 3, 14, 3, 15, 3, 15, 3, 15, 3, 15, 3, 15,
-	3, 15, 3, 15, 3, 16, 3, 16, 3, 17, 3, 17, 3, 18, 3, 18, 3, 19, 3, 19, 3,
-	20, 3, 20, 3, 21, 5, 21, 115, 10, 21, 3, 21, 6, 21, 118, 10, 21, 13, 21,
-	14, 21, 119, 3, 22, 5, 22, 123, 10, 22, 3, 22, 6, 22, 126, 10, 22, 13,
-	22, 14, 22, 127, 3, 22, 3, 22, 6, 22, 132, 10, 22, 13, 22, 14, 22, 133,
-	3, 23, 3, 23, 5, 23, 138, 10, 23, 3, 24, 3, 24, 3, 24, 7, 24, 143, 10,
-	24, 12, 24, 14, 24, 146, 11, 24, 3, 24, 3, 24, 3, 25, 3, 25, 3, 25, 3,
-	26, 6, 26, 154, 10, 26, 13, 26, 14, 26, 155, 3, 26, 7, 26, 159, 10, 26,
-	12, 26, 14, 26, 162, 11, 26, 3, 27, 6, 27, 165, 10, 27, 13, 27, 14, 27,
-	166, 3, 27, 3, 27, 3, 28, 3, 28, 3, 28, 3, 28, 5, 28, 175, 10, 28, 3, 29,
-	3, 29, 3, 30, 3, 30, 3, 30, 3, 30, 3, 30, 3, 31, 3, 31, 3, 31, 3, 31, 3,
-	31, 3, 31, 3, 144, 2, 32, 3, 3, 5, 4, 7, 5, 9, 6, 11, 7, 13, 8, 15, 9,
-	17, 10, 19, 11, 21, 12, 23, 13, 25, 14, 27, 15, 29, 16, 31, 17, 33, 18,
-	35, 19, 37, 20, 39, 21, 41, 22, 43, 23, 45, 24, 47, 25, 49, 26, 51, 27,
-	53, 28, 55, 2, 57, 2, 59, 2, 61, 2, 3, 2, 6, 5, 2, 67, 92, 97, 97, 99,
-	124, 6, 2, 50, 59, 67, 92, 97, 97, 99, 124, 5, 2, 11, 12, 15, 15, 34, 34,
-	3, 2, 50, 59, 2, 196, 2, 3, 3, 2, 2, 2, 2, 5, 3, 2, 2, 2, 2, 7, 3, 2, 2,
-	2, 2, 9, 3, 2, 2, 2, 2, 11, 3, 2, 2, 2, 2, 13, 3, 2, 2, 2, 2, 15, 3, 2,
-	2, 2, 2, 17, 3, 2, 2, 2, 2, 19, 3, 2, 2, 2, 2, 21, 3, 2, 2, 2, 2, 23, 3,
-	2, 2, 2, 2, 25, 3, 2, 2, 2, 2, 27, 3, 2, 2, 2, 2, 29, 3, 2, 2, 2, 2, 31,
-	3, 2, 2, 2, 2, 33, 3, 2, 2, 2, 2, 35, 3, 2, 2, 2, 2, 37, 3, 2, 2, 2, 2,
-	39, 3, 2, 2, 2, 2, 41, 3, 2, 2, 2, 2, 43, 3, 2, 2, 2, 2, 45, 3, 2, 2, 2,
-	2, 47, 3, 2, 2, 2, 2, 49, 3, 2, 2, 2, 2, 51, 3, 2, 2, 2, 2, 53, 3, 2, 2,
-	2, 3, 63, 3, 2, 2, 2, 5, 65, 3, 2, 2, 2, 7, 67, 3, 2, 2, 2, 9, 69, 3, 2,
-	2, 2, 11, 71, 3, 2, 2, 2, 13, 74, 3, 2, 2, 2, 15, 78, 3, 2, 2, 2, 17, 80,
-	3, 2, 2, 2, 19, 83, 3, 2, 2, 2, 21, 85, 3, 2, 2, 2, 23, 88, 3, 2, 2, 2,
-	25, 90, 3, 2, 2, 2, 27, 93, 3, 2, 2, 2, 29, 96, 3, 2, 2, 2, 31, 103, 3,
-	2, 2, 2, 33, 105, 3, 2, 2, 2, 35, 107, 3, 2, 2, 2, 37, 109, 3, 2, 2, 2,
-	39, 111, 3, 2, 2, 2, 41, 114, 3, 2, 2, 2, 43, 122, 3, 2, 2, 2, 45, 137,
-	3, 2, 2, 2, 47, 139, 3, 2, 2, 2, 49, 149, 3, 2, 2, 2, 51, 153, 3, 2, 2,
-	2, 53, 164, 3, 2, 2, 2, 55, 174, 3, 2, 2, 2, 57, 176, 3, 2, 2, 2, 59, 178,
-	3, 2, 2, 2, 61, 183, 3, 2, 2, 2, 63, 64, 7, 35, 2, 2, 64, 4, 3, 2, 2, 2,
-	65, 66, 7, 42, 2, 2, 66, 6, 3, 2, 2, 2, 67, 68, 7, 43, 2, 2, 68, 8, 3,
-	2, 2, 2, 69, 70, 7, 46, 2, 2, 70, 10, 3, 2, 2, 2, 71, 72, 7, 113, 2, 2,
-	72, 73, 7, 116, 2, 2, 73, 12, 3, 2, 2, 2, 74, 75, 7, 99, 2, 2, 75, 76,
-	7, 112, 2, 2, 76, 77, 7, 102, 2, 2, 77, 14, 3, 2, 2, 2, 78, 79, 7, 64,
-	2, 2, 79, 16, 3, 2, 2, 2, 80, 81, 7, 64, 2, 2, 81, 82, 7, 63, 2, 2, 82,
-	18, 3, 2, 2, 2, 83, 84, 7, 62, 2, 2, 84, 20, 3, 2, 2, 2, 85, 86, 7, 62,
-	2, 2, 86, 87, 7, 63, 2, 2, 87, 22, 3, 2, 2, 2, 88, 89, 7, 63, 2, 2, 89,
-	24, 3, 2, 2, 2, 90, 91, 7, 35, 2, 2, 91, 92, 7, 63, 2, 2, 92, 26, 3, 2,
-	2, 2, 93, 94, 7, 107, 2, 2, 94, 95, 7, 112, 2, 2, 95, 28, 3, 2, 2, 2, 96,
-	97, 7, 112, 2, 2, 97, 98, 7, 113, 2, 2, 98, 99, 7, 118, 2, 2, 99, 100,
-	7, 34, 2, 2, 100, 101, 7, 107, 2, 2, 101, 102, 7, 112, 2, 2, 102, 30, 3,
-	2, 2, 2, 103, 104, 7, 44, 2, 2, 104, 32, 3, 2, 2, 2, 105, 106, 7, 39, 2,
-	2, 106, 34, 3, 2, 2, 2, 107, 108, 7, 49, 2, 2, 108, 36, 3, 2, 2, 2, 109,
-	110, 7, 45, 2, 2, 110, 38, 3, 2, 2, 2, 111, 112, 7, 47, 2, 2, 112, 40,
-	3, 2, 2, 2, 113, 115, 7, 47, 2, 2, 114, 113, 3, 2, 2, 2, 114, 115, 3, 2,
-	2, 2, 115, 117, 3, 2, 2, 2, 116, 118, 5, 57, 29, 2, 117, 116, 3, 2, 2,
-	2, 118, 119, 3, 2, 2, 2, 119, 117, 3, 2, 2, 2, 119, 120, 3, 2, 2, 2, 120,
-	42, 3, 2, 2, 2, 121, 123, 7, 47, 2, 2, 122, 121, 3, 2, 2, 2, 122, 123,
-	3, 2, 2, 2, 123, 125, 3, 2, 2, 2, 124, 126, 5, 57, 29, 2, 125, 124, 3,
-	2, 2, 2, 126, 127, 3, 2, 2, 2, 127, 125, 3, 2, 2, 2, 127, 128, 3, 2, 2,
-	2, 128, 129, 3, 2, 2, 2, 129, 131, 7, 48, 2, 2, 130, 132, 5, 57, 29, 2,
-	131, 130, 3, 2, 2, 2, 132, 133, 3, 2, 2, 2, 133, 131, 3, 2, 2, 2, 133,
-	134, 3, 2, 2, 2, 134, 44, 3, 2, 2, 2, 135, 138, 5, 59, 30, 2, 136, 138,
-	5, 61, 31, 2, 137, 135, 3, 2, 2, 2, 137, 136, 3, 2, 2, 2, 138, 46, 3, 2,
-	2, 2, 139, 144, 7, 41, 2, 2, 140, 143, 5, 55, 28, 2, 141, 143, 11, 2, 2,
-	2, 142, 140, 3, 2, 2, 2, 142, 141, 3, 2, 2, 2, 143, 146, 3, 2, 2, 2, 144,
-	145, 3, 2, 2, 2, 144, 142, 3, 2, 2, 2, 145, 147, 3, 2, 2, 2, 146, 144,
-	3, 2, 2, 2, 147, 148, 7, 41, 2, 2, 148, 48, 3, 2, 2, 2, 149, 150, 7, 38,
-	2, 2, 150, 151, 5, 51, 26, 2, 151, 50, 3, 2, 2, 2, 152, 154, 9, 2, 2, 2,
-	153, 152, 3, 2, 2, 2, 154, 155, 3, 2, 2, 2, 155, 153, 3, 2, 2, 2, 155,
-	156, 3, 2, 2, 2, 156, 160, 3, 2, 2, 2, 157, 159, 9, 3, 2, 2, 158, 157,
-	3, 2, 2, 2, 159, 162, 3, 2, 2, 2, 160, 158, 3, 2, 2, 2, 160, 161, 3, 2,
-	2, 2, 161, 52, 3, 2, 2, 2, 162, 160, 3, 2, 2, 2, 163, 165, 9, 4, 2, 2,
-	164, 163, 3, 2, 2, 2, 165, 166, 3, 2, 2, 2, 166, 164, 3, 2, 2, 2, 166,
-	167, 3, 2, 2, 2, 167, 168, 3, 2, 2, 2, 168, 169, 8, 27, 2, 2, 169, 54,
-	3, 2, 2, 2, 170, 171, 7, 94, 2, 2, 171, 175, 7, 41, 2, 2, 172, 173, 7,
-	94, 2, 2, 173, 175, 7, 94, 2, 2, 174, 170, 3, 2, 2, 2, 174, 172, 3, 2,
-	2, 2, 175, 56, 3, 2, 2, 2, 176, 177, 9, 5, 2, 2, 177, 58, 3, 2, 2, 2, 178,
-	179, 7, 118, 2, 2, 179, 180, 7, 116, 2, 2, 180, 181, 7, 119, 2, 2, 181,
-	182, 7, 103, 2, 2, 182, 60, 3, 2, 2, 2, 183, 184, 7, 104, 2, 2, 184, 185,
-	7, 99, 2, 2, 185, 186, 7, 110, 2, 2, 186, 187, 7, 117, 2, 2, 187, 188,
-	7, 103, 2, 2, 188, 62, 3, 2, 2, 2, 15, 2, 114, 119, 122, 127, 133, 137,
-	142, 144, 155, 160, 166, 174, 3, 8, 2, 2,
+	28, 4, 29, 9, 29, 4, 30, 9, 30, 4, 31, 9, 31, 4, 32, 9, 32, 4, 33, 9, 33,
+	4, 34, 9, 34, 4, 35, 9, 35, 4, 36, 9, 36, 4, 37, 9, 37, 3, 2, 3, 2, 3,
+	3, 3, 3, 3, 4, 3, 4, 3, 5, 3, 5, 3, 6, 3, 6, 3, 7, 3, 7, 3, 8, 3, 8, 3,
+	9, 3, 9, 3, 10, 3, 10, 3, 11, 3, 11, 3, 11, 3, 12, 3, 12, 3, 12, 3, 12,
+	3, 13, 3, 13, 3, 14, 3, 14, 3, 14, 3, 15, 3, 15, 3, 16, 3, 16, 3, 16, 3,
+	17, 3, 17, 3, 18, 3, 18, 3, 18, 3, 19, 3, 19, 3, 19, 3, 20, 3, 20, 3, 20,
+	3, 20, 3, 20, 3, 20, 3, 20, 3, 21, 3, 21, 3, 21, 3, 22, 3, 22, 3, 23, 3,
+	23, 3, 24, 3, 24, 3, 25, 3, 25, 3, 26, 3, 26, 3, 27, 5, 27, 140, 10, 27,
+	3, 27, 6, 27, 143, 10, 27, 13, 27, 14, 27, 144, 3, 28, 5, 28, 148, 10,
+	28, 3, 28, 6, 28, 151, 10, 28, 13, 28, 14, 28, 152, 3, 28, 3, 28, 6, 28,
+	157, 10, 28, 13, 28, 14, 28, 158, 3, 29, 3, 29, 5, 29, 163, 10, 29, 3,
+	30, 3, 30, 3, 30, 7, 30, 168, 10, 30, 12, 30, 14, 30, 171, 11, 30, 3, 30,
+	3, 30, 3, 31, 3, 31, 3, 31, 3, 31, 3, 31, 3, 31, 6, 31, 181, 10, 31, 13,
+	31, 14, 31, 182, 3, 31, 3, 31, 7, 31, 187, 10, 31, 12, 31, 14, 31, 190,
+	11, 31, 3, 32, 6, 32, 193, 10, 32, 13, 32, 14, 32, 194, 3, 32, 7, 32, 198,
+	10, 32, 12, 32, 14, 32, 201, 11, 32, 3, 33, 6, 33, 204, 10, 33, 13, 33,
+	14, 33, 205, 3, 33, 3, 33, 3, 34, 3, 34, 3, 34, 3, 34, 5, 34, 214, 10,
+	34, 3, 35, 3, 35, 3, 36, 3, 36, 3, 36, 3, 36, 3, 36, 3, 37, 3, 37, 3, 37,
+	3, 37, 3, 37, 3, 37, 3, 169, 2, 38, 3, 3, 5, 4, 7, 5, 9, 6, 11, 7, 13,
+	8, 15, 9, 17, 10, 19, 11, 21, 12, 23, 13, 25, 14, 27, 15, 29, 16, 31, 17,
+	33, 18, 35, 19, 37, 20, 39, 21, 41, 22, 43, 23, 45, 24, 47, 25, 49, 26,
+	51, 27, 53, 28, 55, 29, 57, 30, 59, 31, 61, 32, 63, 33, 65, 34, 67, 2,
+	69, 2, 71, 2, 73, 2, 3, 2, 6, 5, 2, 67, 92, 97, 97, 99, 124, 6, 2, 50,
+	59, 67, 92, 97, 97, 99, 124, 5, 2, 11, 12, 15, 15, 34, 34, 3, 2, 50, 59,
+	2, 238, 2, 3, 3, 2, 2, 2, 2, 5, 3, 2, 2, 2, 2, 7, 3, 2, 2, 2, 2, 9, 3,
+	2, 2, 2, 2, 11, 3, 2, 2, 2, 2, 13, 3, 2, 2, 2, 2, 15, 3, 2, 2, 2, 2, 17,
+	3, 2, 2, 2, 2, 19, 3, 2, 2, 2, 2, 21, 3, 2, 2, 2, 2, 23, 3, 2, 2, 2, 2,
+	25, 3, 2, 2, 2, 2, 27, 3, 2, 2, 2, 2, 29, 3, 2, 2, 2, 2, 31, 3, 2, 2, 2,
+	2, 33, 3, 2, 2, 2, 2, 35, 3, 2, 2, 2, 2, 37, 3, 2, 2, 2, 2, 39, 3, 2, 2,
+	2, 2, 41, 3, 2, 2, 2, 2, 43, 3, 2, 2, 2, 2, 45, 3, 2, 2, 2, 2, 47, 3, 2,
+	2, 2, 2, 49, 3, 2, 2, 2, 2, 51, 3, 2, 2, 2, 2, 53, 3, 2, 2, 2, 2, 55, 3,
+	2, 2, 2, 2, 57, 3, 2, 2, 2, 2, 59, 3, 2, 2, 2, 2, 61, 3, 2, 2, 2, 2, 63,
+	3, 2, 2, 2, 2, 65, 3, 2, 2, 2, 3, 75, 3, 2, 2, 2, 5, 77, 3, 2, 2, 2, 7,
+	79, 3, 2, 2, 2, 9, 81, 3, 2, 2, 2, 11, 83, 3, 2, 2, 2, 13, 85, 3, 2, 2,
+	2, 15, 87, 3, 2, 2, 2, 17, 89, 3, 2, 2, 2, 19, 91, 3, 2, 2, 2, 21, 93,
+	3, 2, 2, 2, 23, 96, 3, 2, 2, 2, 25, 100, 3, 2, 2, 2, 27, 102, 3, 2, 2,
+	2, 29, 105, 3, 2, 2, 2, 31, 107, 3, 2, 2, 2, 33, 110, 3, 2, 2, 2, 35, 112,
+	3, 2, 2, 2, 37, 115, 3, 2, 2, 2, 39, 118, 3, 2, 2, 2, 41, 125, 3, 2, 2,
+	2, 43, 128, 3, 2, 2, 2, 45, 130, 3, 2, 2, 2, 47, 132, 3, 2, 2, 2, 49, 134,
+	3, 2, 2, 2, 51, 136, 3, 2, 2, 2, 53, 139, 3, 2, 2, 2, 55, 147, 3, 2, 2,
+	2, 57, 162, 3, 2, 2, 2, 59, 164, 3, 2, 2, 2, 61, 174, 3, 2, 2, 2, 63, 192,
+	3, 2, 2, 2, 65, 203, 3, 2, 2, 2, 67, 213, 3, 2, 2, 2, 69, 215, 3, 2, 2,
+	2, 71, 217, 3, 2, 2, 2, 73, 222, 3, 2, 2, 2, 75, 76, 7, 35, 2, 2, 76, 4,
+	3, 2, 2, 2, 77, 78, 7, 42, 2, 2, 78, 6, 3, 2, 2, 2, 79, 80, 7, 43, 2, 2,
+	80, 8, 3, 2, 2, 2, 81, 82, 7, 46, 2, 2, 82, 10, 3, 2, 2, 2, 83, 84, 7,
+	93, 2, 2, 84, 12, 3, 2, 2, 2, 85, 86, 7, 95, 2, 2, 86, 14, 3, 2, 2, 2,
+	87, 88, 7, 125, 2, 2, 88, 16, 3, 2, 2, 2, 89, 90, 7, 127, 2, 2, 90, 18,
+	3, 2, 2, 2, 91, 92, 7, 60, 2, 2, 92, 20, 3, 2, 2, 2, 93, 94, 7, 113, 2,
+	2, 94, 95, 7, 116, 2, 2, 95, 22, 3, 2, 2, 2, 96, 97, 7, 99, 2, 2, 97, 98,
+	7, 112, 2, 2, 98, 99, 7, 102, 2, 2, 99, 24, 3, 2, 2, 2, 100, 101, 7, 64,
+	2, 2, 101, 26, 3, 2, 2, 2, 102, 103, 7, 64, 2, 2, 103, 104, 7, 63, 2, 2,
+	104, 28, 3, 2, 2, 2, 105, 106, 7, 62, 2, 2, 106, 30, 3, 2, 2, 2, 107, 108,
+	7, 62, 2, 2, 108, 109, 7, 63, 2, 2, 109, 32, 3, 2, 2, 2, 110, 111, 7, 63,
+	2, 2, 111, 34, 3, 2, 2, 2, 112, 113, 7, 35, 2, 2, 113, 114, 7, 63, 2, 2,
+	114, 36, 3, 2, 2, 2, 115, 116, 7, 107, 2, 2, 116, 117, 7, 112, 2, 2, 117,
+	38, 3, 2, 2, 2, 118, 119, 7, 112, 2, 2, 119, 120, 7, 113, 2, 2, 120, 121,
+	7, 118, 2, 2, 121, 122, 7, 34, 2, 2, 122, 123, 7, 107, 2, 2, 123, 124,
+	7, 112, 2, 2, 124, 40, 3, 2, 2, 2, 125, 126, 7, 44, 2, 2, 126, 127, 7,
+	44, 2, 2, 127, 42, 3, 2, 2, 2, 128, 129, 7, 44, 2, 2, 129, 44, 3, 2, 2,
+	2, 130, 131, 7, 39, 2, 2, 131, 46, 3, 2, 2, 2, 132, 133, 7, 49, 2, 2, 133,
+	48, 3, 2, 2, 2, 134, 135, 7, 45, 2, 2, 135, 50, 3, 2, 2, 2, 136, 137, 7,
+	47, 2, 2, 137, 52, 3, 2, 2, 2, 138, 140, 7, 47, 2, 2, 139, 138, 3, 2, 2,
+	2, 139, 140, 3, 2, 2, 2, 140, 142, 3, 2, 2, 2, 141, 143, 5, 69, 35, 2,
+	142, 141, 3, 2, 2, 2, 143, 144, 3, 2, 2, 2, 144, 142, 3, 2, 2, 2, 144,
+	145, 3, 2, 2, 2, 145, 54, 3, 2, 2, 2, 146, 148, 7, 47, 2, 2, 147, 146,
+	3, 2, 2, 2, 147, 148, 3, 2, 2, 2, 148, 150, 3, 2, 2, 2, 149, 151, 5, 69,
+	35, 2, 150, 149, 3, 2, 2, 2, 151, 152, 3, 2, 2, 2, 152, 150, 3, 2, 2, 2,
+	152, 153, 3, 2, 2, 2, 153, 154, 3, 2, 2, 2, 154, 156, 7, 48, 2, 2, 155,
+	157, 5, 69, 35, 2, 156, 155, 3, 2, 2, 2, 157, 158, 3, 2, 2, 2, 158, 156,
+	3, 2, 2, 2, 158, 159, 3, 2, 2, 2, 159, 56, 3, 2, 2, 2, 160, 163, 5, 71,
+	36, 2, 161, 163, 5, 73, 37, 2, 162, 160, 3, 2, 2, 2, 162, 161, 3, 2, 2,
+	2, 163, 58, 3, 2, 2, 2, 164, 169, 7, 41, 2, 2, 165, 168, 5, 67, 34, 2,
+	166, 168, 11, 2, 2, 2, 167, 165, 3, 2, 2, 2, 167, 166, 3, 2, 2, 2, 168,
+	171, 3, 2, 2, 2, 169, 170, 3, 2, 2, 2, 169, 167, 3, 2, 2, 2, 170, 172,
+	3, 2, 2, 2, 171, 169, 3, 2, 2, 2, 172, 173, 7, 41, 2, 2, 173, 60, 3, 2,
+	2, 2, 174, 175, 7, 38, 2, 2, 175, 188, 5, 63, 32, 2, 176, 177, 7, 48, 2,
+	2, 177, 187, 5, 63, 32, 2, 178, 180, 7, 93, 2, 2, 179, 181, 5, 69, 35,
+	2, 180, 179, 3, 2, 2, 2, 181, 182, 3, 2, 2, 2, 182, 180, 3, 2, 2, 2, 182,
+	183, 3, 2, 2, 2, 183, 184, 3, 2, 2, 2, 184, 185, 7, 95, 2, 2, 185, 187,
+	3, 2, 2, 2, 186, 176, 3, 2, 2, 2, 186, 178, 3, 2, 2, 2, 187, 190, 3, 2,
+	2, 2, 188, 186, 3, 2, 2, 2, 188, 189, 3, 2, 2, 2, 189, 62, 3, 2, 2, 2,
+	190, 188, 3, 2, 2, 2, 191, 193, 9, 2, 2, 2, 192, 191, 3, 2, 2, 2, 193,
+	194, 3, 2, 2, 2, 194, 192, 3, 2, 2, 2, 194, 195, 3, 2, 2, 2, 195, 199,
+	3, 2, 2, 2, 196, 198, 9, 3, 2, 2, 197, 196, 3, 2, 2, 2, 198, 201, 3, 2,
+	2, 2, 199, 197, 3, 2, 2, 2, 199, 200, 3, 2, 2, 2, 200, 64, 3, 2, 2, 2,
+	201, 199, 3, 2, 2, 2, 202, 204, 9, 4, 2, 2, 203, 202, 3, 2, 2, 2, 204,
+	205, 3, 2, 2, 2, 205, 203, 3, 2, 2, 2, 205, 206, 3, 2, 2, 2, 206, 207,
+	3, 2, 2, 2, 207, 208, 8, 33, 2, 2, 208, 66, 3, 2, 2, 2, 209, 210, 7, 94,
+	2, 2, 210, 214, 7, 41, 2, 2, 211, 212, 7, 94, 2, 2, 212, 214, 7, 94, 2,
+	2, 213, 209, 3, 2, 2, 2, 213, 211, 3, 2, 2, 2, 214, 68, 3, 2, 2, 2, 215,
+	216, 9, 5, 2, 2, 216, 70, 3, 2, 2, 2, 217, 218, 7, 118, 2, 2, 218, 219,
+	7, 116, 2, 2, 219, 220, 7, 119, 2, 2, 220, 221, 7, 103, 2, 2, 221, 72,
+	3, 2, 2, 2, 222, 223, 7, 104, 2, 2, 223, 224, 7, 99, 2, 2, 224, 225, 7,
+	110, 2, 2, 225, 226, 7, 117, 2, 2, 226, 227, 7, 103, 2, 2, 227, 74, 3,
+	2, 2, 2, 18, 2, 139, 144, 147, 152, 158, 162, 167, 169, 182, 186, 188,
+	194, 199, 205, 213, 3, 8, 2, 2,
 }
 
 var lexerDeserializer = antlr.NewATNDeserializer(nil)
@@ -112,22 +130,22 @@ var lexerModeNames = []string{
 }
 
 var lexerLiteralNames = []string{
-	"", "'!'", "'('", "')'", "','", "'or'", "'and'", "'>'", "'>='", "'<'",
-	"'<='", "'='", "'!='", "'in'", "'not in'", "'*'", "'%'", "'/'", "'+'",
-	"'-'",
+	"", "'!'", "'('", "')'", "','", "'['", "']'", "'{'", "'}'", "':'", "'or'",
+	"'and'", "'>'", "'>='", "'<'", "'<='", "'='", "'!='", "'in'", "'not in'",
+	"'**'", "'*'", "'%'", "'/'", "'+'", "'-'",
 }
 
 var lexerSymbolicNames = []string{
-	"", "", "", "", "", "OR", "AND", "GT", "GTE", "LT", "LTE", "EQ", "NEQ",
-	"IN", "NOTIN", "MUL", "MOD", "DIV", "ADD", "SUB", "INT", "FLOAT", "BOOLEAN",
-	"STRING", "VAR", "IDENTIFIER", "WS",
+	"", "", "", "", "", "", "", "", "", "", "OR", "AND", "GT", "GTE", "LT",
+	"LTE", "EQ", "NEQ", "IN", "NOTIN", "POW", "MUL", "MOD", "DIV", "ADD", "SUB",
+	"INT", "FLOAT", "BOOLEAN", "STRING", "VAR", "IDENTIFIER", "WS",
 }
 
 var lexerRuleNames = []string{
-	"T__0", "T__1", "T__2", "T__3", "OR", "AND", "GT", "GTE", "LT", "LTE",
-	"EQ", "NEQ", "IN", "NOTIN", "MUL", "MOD", "DIV", "ADD", "SUB", "INT", "FLOAT",
-	"BOOLEAN", "STRING", "VAR", "IDENTIFIER", "WS", "ESC", "DIGIT", "TRUE",
-	"FALSE",
+	"T__0", "T__1", "T__2", "T__3", "T__4", "T__5", "T__6", "T__7", "T__8",
+	"OR", "AND", "GT", "GTE", "LT", "LTE", "EQ", "NEQ", "IN", "NOTIN", "POW",
+	"MUL", "MOD", "DIV", "ADD", "SUB", "INT", "FLOAT", "BOOLEAN", "STRING",
+	"VAR", "IDENTIFIER", "WS", "ESC", "DIGIT", "TRUE", "FALSE",
 }
 
 type ExprLexer struct {
@@ -169,26 +187,32 @@ const (
 	ExprLexerT__1       = 2
 	ExprLexerT__2       = 3
 	ExprLexerT__3       = 4
-	ExprLexerOR         = 5
-	ExprLexerAND        = 6
-	ExprLexerGT         = 7
-	ExprLexerGTE        = 8
-	ExprLexerLT         = 9
-	ExprLexerLTE        = 10
-	ExprLexerEQ         = 11
-	ExprLexerNEQ        = 12
-	ExprLexerIN         = 13
-	ExprLexerNOTIN      = 14
-	ExprLexerMUL        = 15
-	ExprLexerMOD        = 16
-	ExprLexerDIV        = 17
-	ExprLexerADD        = 18
-	ExprLexerSUB        = 19
-	ExprLexerINT        = 20
-	ExprLexerFLOAT      = 21
-	ExprLexerBOOLEAN    = 22
-	ExprLexerSTRING     = 23
-	ExprLexerVAR        = 24
-	ExprLexerIDENTIFIER = 25
-	ExprLexerWS         = 26
+	ExprLexerT__4       = 5
+	ExprLexerT__5       = 6
+	ExprLexerT__6       = 7
+	ExprLexerT__7       = 8
+	ExprLexerT__8       = 9
+	ExprLexerOR         = 10
+	ExprLexerAND        = 11
+	ExprLexerGT         = 12
+	ExprLexerGTE        = 13
+	ExprLexerLT         = 14
+	ExprLexerLTE        = 15
+	ExprLexerEQ         = 16
+	ExprLexerNEQ        = 17
+	ExprLexerIN         = 18
+	ExprLexerNOTIN      = 19
+	ExprLexerPOW        = 20
+	ExprLexerMUL        = 21
+	ExprLexerMOD        = 22
+	ExprLexerDIV        = 23
+	ExprLexerADD        = 24
+	ExprLexerSUB        = 25
+	ExprLexerINT        = 26
+	ExprLexerFLOAT      = 27
+	ExprLexerBOOLEAN    = 28
+	ExprLexerSTRING     = 29
+	ExprLexerVAR        = 30
+	ExprLexerIDENTIFIER = 31
+	ExprLexerWS         = 32
 )
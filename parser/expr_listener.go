@@ -44,6 +44,9 @@ type ExprListener interface {
 	// EnterBoolBracket is called when entering the BoolBracket production.
 	EnterBoolBracket(c *BoolBracketContext)
 
+	// EnterMapExpression is called when entering the MapExpression production.
+	EnterMapExpression(c *MapExpressionContext)
+
 	// EnterBracket is called when entering the Bracket production.
 	EnterBracket(c *BracketContext)
 
@@ -59,6 +62,12 @@ type ExprListener interface {
 	// EnterAddSub is called when entering the AddSub production.
 	EnterAddSub(c *AddSubContext)
 
+	// EnterListExpression is called when entering the ListExpression production.
+	EnterListExpression(c *ListExpressionContext)
+
+	// EnterPow is called when entering the Pow production.
+	EnterPow(c *PowContext)
+
 	// EnterExpressionFunction is called when entering the ExpressionFunction production.
 	EnterExpressionFunction(c *ExpressionFunctionContext)
 
@@ -80,6 +89,18 @@ type ExprListener interface {
 	// EnterNumberList is called when entering the numberList production.
 	EnterNumberList(c *NumberListContext)
 
+	// EnterListLiteral is called when entering the listLiteral production.
+	EnterListLiteral(c *ListLiteralContext)
+
+	// EnterMapLiteral is called when entering the mapLiteral production.
+	EnterMapLiteral(c *MapLiteralContext)
+
+	// EnterPair is called when entering the pair production.
+	EnterPair(c *PairContext)
+
+	// EnterElement is called when entering the element production.
+	EnterElement(c *ElementContext)
+
 	// EnterFunction is called when entering the function production.
 	EnterFunction(c *FunctionContext)
 
@@ -125,6 +146,9 @@ type ExprListener interface {
 	// ExitBoolBracket is called when exiting the BoolBracket production.
 	ExitBoolBracket(c *BoolBracketContext)
 
+	// ExitMapExpression is called when exiting the MapExpression production.
+	ExitMapExpression(c *MapExpressionContext)
+
 	// ExitBracket is called when exiting the Bracket production.
 	ExitBracket(c *BracketContext)
 
@@ -140,6 +164,12 @@ type ExprListener interface {
 	// ExitAddSub is called when exiting the AddSub production.
 	ExitAddSub(c *AddSubContext)
 
+	// ExitListExpression is called when exiting the ListExpression production.
+	ExitListExpression(c *ListExpressionContext)
+
+	// ExitPow is called when exiting the Pow production.
+	ExitPow(c *PowContext)
+
 	// ExitExpressionFunction is called when exiting the ExpressionFunction production.
 	ExitExpressionFunction(c *ExpressionFunctionContext)
 
@@ -161,6 +191,18 @@ type ExprListener interface {
 	// ExitNumberList is called when exiting the numberList production.
 	ExitNumberList(c *NumberListContext)
 
+	// ExitListLiteral is called when exiting the listLiteral production.
+	ExitListLiteral(c *ListLiteralContext)
+
+	// ExitMapLiteral is called when exiting the mapLiteral production.
+	ExitMapLiteral(c *MapLiteralContext)
+
+	// ExitPair is called when exiting the pair production.
+	ExitPair(c *PairContext)
+
+	// ExitElement is called when exiting the element production.
+	ExitElement(c *ElementContext)
+
 	// ExitFunction is called when exiting the function production.
 	ExitFunction(c *FunctionContext)
 
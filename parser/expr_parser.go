@@ -16,82 +16,107 @@ var _ = reflect.Copy
 var _ = strconv.Itoa
 
 var parserATN = []uint16{
-	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 28, 131,
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 34, 186,
 	4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7,
-	4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 5, 3, 35, 10, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 5, 3, 45, 10, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 7, 3, 56, 10, 3, 12, 3, 14,
-	3, 59, 11, 3, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3,
-	4, 3, 4, 3, 4, 5, 4, 73, 10, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 7,
-	4, 81, 10, 4, 12, 4, 14, 4, 84, 11, 4, 3, 5, 3, 5, 3, 6, 3, 6, 3, 6, 3,
-	6, 7, 6, 92, 10, 6, 12, 6, 14, 6, 95, 11, 6, 3, 6, 3, 6, 3, 7, 3, 7, 3,
-	7, 3, 7, 7, 7, 103, 10, 7, 12, 7, 14, 7, 106, 11, 7, 3, 7, 3, 7, 3, 8,
-	3, 8, 3, 8, 5, 8, 113, 10, 8, 3, 8, 3, 8, 3, 9, 3, 9, 3, 9, 7, 9, 120,
-	10, 9, 12, 9, 14, 9, 123, 11, 9, 3, 10, 3, 10, 3, 10, 3, 10, 5, 10, 129,
-	10, 10, 3, 10, 2, 4, 4, 6, 11, 2, 4, 6, 8, 10, 12, 14, 16, 18, 2, 8, 3,
-	2, 9, 14, 3, 2, 15, 16, 3, 2, 13, 14, 4, 2, 17, 17, 19, 19, 3, 2, 20, 21,
-	3, 2, 22, 23, 2, 147, 2, 20, 3, 2, 2, 2, 4, 44, 3, 2, 2, 2, 6, 72, 3, 2,
-	2, 2, 8, 85, 3, 2, 2, 2, 10, 87, 3, 2, 2, 2, 12, 98, 3, 2, 2, 2, 14, 109,
-	3, 2, 2, 2, 16, 116, 3, 2, 2, 2, 18, 128, 3, 2, 2, 2, 20, 21, 5, 4, 3,
-	2, 21, 22, 7, 2, 2, 3, 22, 3, 3, 2, 2, 2, 23, 24, 8, 3, 1, 2, 24, 25, 7,
-	3, 2, 2, 25, 45, 5, 4, 3, 13, 26, 27, 5, 6, 4, 2, 27, 28, 9, 2, 2, 2, 28,
-	29, 5, 6, 4, 2, 29, 45, 3, 2, 2, 2, 30, 31, 5, 6, 4, 2, 31, 34, 9, 3, 2,
-	2, 32, 35, 5, 10, 6, 2, 33, 35, 5, 12, 7, 2, 34, 32, 3, 2, 2, 2, 34, 33,
-	3, 2, 2, 2, 35, 45, 3, 2, 2, 2, 36, 45, 7, 24, 2, 2, 37, 45, 5, 14, 8,
-	2, 38, 39, 7, 4, 2, 2, 39, 40, 5, 4, 3, 2, 40, 41, 7, 5, 2, 2, 41, 45,
-	3, 2, 2, 2, 42, 45, 7, 26, 2, 2, 43, 45, 7, 27, 2, 2, 44, 23, 3, 2, 2,
-	2, 44, 26, 3, 2, 2, 2, 44, 30, 3, 2, 2, 2, 44, 36, 3, 2, 2, 2, 44, 37,
-	3, 2, 2, 2, 44, 38, 3, 2, 2, 2, 44, 42, 3, 2, 2, 2, 44, 43, 3, 2, 2, 2,
-	45, 57, 3, 2, 2, 2, 46, 47, 12, 12, 2, 2, 47, 48, 9, 4, 2, 2, 48, 56, 5,
-	4, 3, 13, 49, 50, 12, 9, 2, 2, 50, 51, 7, 8, 2, 2, 51, 56, 5, 4, 3, 10,
-	52, 53, 12, 8, 2, 2, 53, 54, 7, 7, 2, 2, 54, 56, 5, 4, 3, 9, 55, 46, 3,
-	2, 2, 2, 55, 49, 3, 2, 2, 2, 55, 52, 3, 2, 2, 2, 56, 59, 3, 2, 2, 2, 57,
-	55, 3, 2, 2, 2, 57, 58, 3, 2, 2, 2, 58, 5, 3, 2, 2, 2, 59, 57, 3, 2, 2,
-	2, 60, 61, 8, 4, 1, 2, 61, 62, 7, 21, 2, 2, 62, 73, 5, 6, 4, 10, 63, 73,
-	7, 25, 2, 2, 64, 73, 7, 27, 2, 2, 65, 73, 5, 8, 5, 2, 66, 73, 7, 26, 2,
-	2, 67, 73, 5, 14, 8, 2, 68, 69, 7, 4, 2, 2, 69, 70, 5, 6, 4, 2, 70, 71,
-	7, 5, 2, 2, 71, 73, 3, 2, 2, 2, 72, 60, 3, 2, 2, 2, 72, 63, 3, 2, 2, 2,
-	72, 64, 3, 2, 2, 2, 72, 65, 3, 2, 2, 2, 72, 66, 3, 2, 2, 2, 72, 67, 3,
-	2, 2, 2, 72, 68, 3, 2, 2, 2, 73, 82, 3, 2, 2, 2, 74, 75, 12, 11, 2, 2,
-	75, 76, 9, 5, 2, 2, 76, 81, 5, 6, 4, 12, 77, 78, 12, 9, 2, 2, 78, 79, 9,
-	6, 2, 2, 79, 81, 5, 6, 4, 10, 80, 74, 3, 2, 2, 2, 80, 77, 3, 2, 2, 2, 81,
-	84, 3, 2, 2, 2, 82, 80, 3, 2, 2, 2, 82, 83, 3, 2, 2, 2, 83, 7, 3, 2, 2,
-	2, 84, 82, 3, 2, 2, 2, 85, 86, 9, 7, 2, 2, 86, 9, 3, 2, 2, 2, 87, 88, 7,
-	4, 2, 2, 88, 93, 7, 25, 2, 2, 89, 90, 7, 6, 2, 2, 90, 92, 7, 25, 2, 2,
-	91, 89, 3, 2, 2, 2, 92, 95, 3, 2, 2, 2, 93, 91, 3, 2, 2, 2, 93, 94, 3,
-	2, 2, 2, 94, 96, 3, 2, 2, 2, 95, 93, 3, 2, 2, 2, 96, 97, 7, 5, 2, 2, 97,
-	11, 3, 2, 2, 2, 98, 99, 7, 4, 2, 2, 99, 104, 5, 8, 5, 2, 100, 101, 7, 6,
-	2, 2, 101, 103, 5, 8, 5, 2, 102, 100, 3, 2, 2, 2, 103, 106, 3, 2, 2, 2,
-	104, 102, 3, 2, 2, 2, 104, 105, 3, 2, 2, 2, 105, 107, 3, 2, 2, 2, 106,
-	104, 3, 2, 2, 2, 107, 108, 7, 5, 2, 2, 108, 13, 3, 2, 2, 2, 109, 110, 7,
-	27, 2, 2, 110, 112, 7, 4, 2, 2, 111, 113, 5, 16, 9, 2, 112, 111, 3, 2,
-	2, 2, 112, 113, 3, 2, 2, 2, 113, 114, 3, 2, 2, 2, 114, 115, 7, 5, 2, 2,
-	115, 15, 3, 2, 2, 2, 116, 121, 5, 18, 10, 2, 117, 118, 7, 6, 2, 2, 118,
-	120, 5, 18, 10, 2, 119, 117, 3, 2, 2, 2, 120, 123, 3, 2, 2, 2, 121, 119,
-	3, 2, 2, 2, 121, 122, 3, 2, 2, 2, 122, 17, 3, 2, 2, 2, 123, 121, 3, 2,
-	2, 2, 124, 129, 7, 26, 2, 2, 125, 129, 5, 8, 5, 2, 126, 129, 7, 24, 2,
-	2, 127, 129, 7, 25, 2, 2, 128, 124, 3, 2, 2, 2, 128, 125, 3, 2, 2, 2, 128,
-	126, 3, 2, 2, 2, 128, 127, 3, 2, 2, 2, 129, 19, 3, 2, 2, 2, 14, 34, 44,
-	55, 57, 72, 80, 82, 93, 104, 112, 121, 128,
+	4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12, 4, 13,
+	9, 13, 4, 14, 9, 14, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 5, 2, 35, 10,
+	2, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	3, 5, 3, 49, 10, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 5,
+	3, 59, 10, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 7,
+	3, 70, 10, 3, 12, 3, 14, 3, 73, 11, 3, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3,
+	4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 5, 4, 89, 10, 4, 3,
+	4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 3, 4, 7, 4, 100, 10, 4, 12,
+	4, 14, 4, 103, 11, 4, 3, 5, 3, 5, 3, 6, 3, 6, 3, 6, 3, 6, 7, 6, 111, 10,
+	6, 12, 6, 14, 6, 114, 11, 6, 3, 6, 3, 6, 3, 7, 3, 7, 3, 7, 3, 7, 7, 7,
+	122, 10, 7, 12, 7, 14, 7, 125, 11, 7, 3, 7, 3, 7, 3, 8, 3, 8, 3, 8, 3,
+	8, 3, 8, 3, 8, 7, 8, 135, 10, 8, 12, 8, 14, 8, 138, 11, 8, 3, 8, 3, 8,
+	5, 8, 142, 10, 8, 3, 9, 3, 9, 3, 9, 3, 9, 3, 9, 3, 9, 7, 9, 150, 10, 9,
+	12, 9, 14, 9, 153, 11, 9, 3, 9, 3, 9, 5, 9, 157, 10, 9, 3, 10, 3, 10, 3,
+	10, 3, 10, 3, 11, 3, 11, 5, 11, 165, 10, 11, 3, 12, 3, 12, 3, 12, 5, 12,
+	170, 10, 12, 3, 12, 3, 12, 3, 13, 3, 13, 3, 13, 7, 13, 177, 10, 13, 12,
+	13, 14, 13, 180, 11, 13, 3, 14, 3, 14, 5, 14, 184, 10, 14, 3, 14, 2, 4,
+	4, 6, 15, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20, 22, 24, 26, 2, 8, 3, 2, 14,
+	19, 3, 2, 20, 21, 3, 2, 18, 19, 3, 2, 23, 25, 3, 2, 26, 27, 3, 2, 28, 29,
+	2, 206, 2, 34, 3, 2, 2, 2, 4, 58, 3, 2, 2, 2, 6, 88, 3, 2, 2, 2, 8, 104,
+	3, 2, 2, 2, 10, 106, 3, 2, 2, 2, 12, 117, 3, 2, 2, 2, 14, 141, 3, 2, 2,
+	2, 16, 156, 3, 2, 2, 2, 18, 158, 3, 2, 2, 2, 20, 164, 3, 2, 2, 2, 22, 166,
+	3, 2, 2, 2, 24, 173, 3, 2, 2, 2, 26, 183, 3, 2, 2, 2, 28, 29, 5, 6, 4,
+	2, 29, 30, 7, 2, 2, 3, 30, 35, 3, 2, 2, 2, 31, 32, 5, 4, 3, 2, 32, 33,
+	7, 2, 2, 3, 33, 35, 3, 2, 2, 2, 34, 28, 3, 2, 2, 2, 34, 31, 3, 2, 2, 2,
+	35, 3, 3, 2, 2, 2, 36, 37, 8, 3, 1, 2, 37, 38, 7, 3, 2, 2, 38, 59, 5, 4,
+	3, 13, 39, 40, 5, 6, 4, 2, 40, 41, 9, 2, 2, 2, 41, 42, 5, 6, 4, 2, 42,
+	59, 3, 2, 2, 2, 43, 44, 5, 6, 4, 2, 44, 48, 9, 3, 2, 2, 45, 49, 5, 10,
+	6, 2, 46, 49, 5, 12, 7, 2, 47, 49, 5, 6, 4, 2, 48, 45, 3, 2, 2, 2, 48,
+	46, 3, 2, 2, 2, 48, 47, 3, 2, 2, 2, 49, 59, 3, 2, 2, 2, 50, 59, 7, 30,
+	2, 2, 51, 59, 5, 22, 12, 2, 52, 53, 7, 4, 2, 2, 53, 54, 5, 4, 3, 2, 54,
+	55, 7, 5, 2, 2, 55, 59, 3, 2, 2, 2, 56, 59, 7, 32, 2, 2, 57, 59, 7, 33,
+	2, 2, 58, 36, 3, 2, 2, 2, 58, 39, 3, 2, 2, 2, 58, 43, 3, 2, 2, 2, 58, 50,
+	3, 2, 2, 2, 58, 51, 3, 2, 2, 2, 58, 52, 3, 2, 2, 2, 58, 56, 3, 2, 2, 2,
+	58, 57, 3, 2, 2, 2, 59, 71, 3, 2, 2, 2, 60, 61, 12, 12, 2, 2, 61, 62, 9,
+	4, 2, 2, 62, 70, 5, 4, 3, 13, 63, 64, 12, 9, 2, 2, 64, 65, 7, 13, 2, 2,
+	65, 70, 5, 4, 3, 10, 66, 67, 12, 8, 2, 2, 67, 68, 7, 12, 2, 2, 68, 70,
+	5, 4, 3, 9, 69, 60, 3, 2, 2, 2, 69, 63, 3, 2, 2, 2, 69, 66, 3, 2, 2, 2,
+	70, 73, 3, 2, 2, 2, 71, 69, 3, 2, 2, 2, 71, 72, 3, 2, 2, 2, 72, 5, 3, 2,
+	2, 2, 73, 71, 3, 2, 2, 2, 74, 75, 8, 4, 1, 2, 75, 76, 7, 27, 2, 2, 76,
+	89, 5, 6, 4, 12, 77, 89, 7, 31, 2, 2, 78, 89, 7, 33, 2, 2, 79, 89, 5, 8,
+	5, 2, 80, 89, 7, 32, 2, 2, 81, 89, 5, 22, 12, 2, 82, 83, 7, 4, 2, 2, 83,
+	84, 5, 6, 4, 2, 84, 85, 7, 5, 2, 2, 85, 89, 3, 2, 2, 2, 86, 89, 5, 14,
+	8, 2, 87, 89, 5, 16, 9, 2, 88, 74, 3, 2, 2, 2, 88, 77, 3, 2, 2, 2, 88,
+	78, 3, 2, 2, 2, 88, 79, 3, 2, 2, 2, 88, 80, 3, 2, 2, 2, 88, 81, 3, 2, 2,
+	2, 88, 82, 3, 2, 2, 2, 88, 86, 3, 2, 2, 2, 88, 87, 3, 2, 2, 2, 89, 101,
+	3, 2, 2, 2, 90, 91, 12, 14, 2, 2, 91, 92, 7, 22, 2, 2, 92, 100, 5, 6, 4,
+	14, 93, 94, 12, 13, 2, 2, 94, 95, 9, 5, 2, 2, 95, 100, 5, 6, 4, 14, 96,
+	97, 12, 11, 2, 2, 97, 98, 9, 6, 2, 2, 98, 100, 5, 6, 4, 12, 99, 90, 3,
+	2, 2, 2, 99, 93, 3, 2, 2, 2, 99, 96, 3, 2, 2, 2, 100, 103, 3, 2, 2, 2,
+	101, 99, 3, 2, 2, 2, 101, 102, 3, 2, 2, 2, 102, 7, 3, 2, 2, 2, 103, 101,
+	3, 2, 2, 2, 104, 105, 9, 7, 2, 2, 105, 9, 3, 2, 2, 2, 106, 107, 7, 4, 2,
+	2, 107, 112, 7, 31, 2, 2, 108, 109, 7, 6, 2, 2, 109, 111, 7, 31, 2, 2,
+	110, 108, 3, 2, 2, 2, 111, 114, 3, 2, 2, 2, 112, 110, 3, 2, 2, 2, 112,
+	113, 3, 2, 2, 2, 113, 115, 3, 2, 2, 2, 114, 112, 3, 2, 2, 2, 115, 116,
+	7, 5, 2, 2, 116, 11, 3, 2, 2, 2, 117, 118, 7, 4, 2, 2, 118, 123, 5, 8,
+	5, 2, 119, 120, 7, 6, 2, 2, 120, 122, 5, 8, 5, 2, 121, 119, 3, 2, 2, 2,
+	122, 125, 3, 2, 2, 2, 123, 121, 3, 2, 2, 2, 123, 124, 3, 2, 2, 2, 124,
+	126, 3, 2, 2, 2, 125, 123, 3, 2, 2, 2, 126, 127, 7, 5, 2, 2, 127, 13, 3,
+	2, 2, 2, 128, 129, 7, 7, 2, 2, 129, 142, 7, 8, 2, 2, 130, 131, 7, 7, 2,
+	2, 131, 136, 5, 20, 11, 2, 132, 133, 7, 6, 2, 2, 133, 135, 5, 20, 11, 2,
+	134, 132, 3, 2, 2, 2, 135, 138, 3, 2, 2, 2, 136, 134, 3, 2, 2, 2, 136,
+	137, 3, 2, 2, 2, 137, 139, 3, 2, 2, 2, 138, 136, 3, 2, 2, 2, 139, 140,
+	7, 8, 2, 2, 140, 142, 3, 2, 2, 2, 141, 128, 3, 2, 2, 2, 141, 130, 3, 2,
+	2, 2, 142, 15, 3, 2, 2, 2, 143, 144, 7, 9, 2, 2, 144, 157, 7, 10, 2, 2,
+	145, 146, 7, 9, 2, 2, 146, 151, 5, 18, 10, 2, 147, 148, 7, 6, 2, 2, 148,
+	150, 5, 18, 10, 2, 149, 147, 3, 2, 2, 2, 150, 153, 3, 2, 2, 2, 151, 149,
+	3, 2, 2, 2, 151, 152, 3, 2, 2, 2, 152, 154, 3, 2, 2, 2, 153, 151, 3, 2,
+	2, 2, 154, 155, 7, 10, 2, 2, 155, 157, 3, 2, 2, 2, 156, 143, 3, 2, 2, 2,
+	156, 145, 3, 2, 2, 2, 157, 17, 3, 2, 2, 2, 158, 159, 7, 31, 2, 2, 159,
+	160, 7, 11, 2, 2, 160, 161, 5, 20, 11, 2, 161, 19, 3, 2, 2, 2, 162, 165,
+	5, 6, 4, 2, 163, 165, 5, 4, 3, 2, 164, 162, 3, 2, 2, 2, 164, 163, 3, 2,
+	2, 2, 165, 21, 3, 2, 2, 2, 166, 167, 7, 33, 2, 2, 167, 169, 7, 4, 2, 2,
+	168, 170, 5, 24, 13, 2, 169, 168, 3, 2, 2, 2, 169, 170, 3, 2, 2, 2, 170,
+	171, 3, 2, 2, 2, 171, 172, 7, 5, 2, 2, 172, 23, 3, 2, 2, 2, 173, 178, 5,
+	26, 14, 2, 174, 175, 7, 6, 2, 2, 175, 177, 5, 26, 14, 2, 176, 174, 3, 2,
+	2, 2, 177, 180, 3, 2, 2, 2, 178, 176, 3, 2, 2, 2, 178, 179, 3, 2, 2, 2,
+	179, 25, 3, 2, 2, 2, 180, 178, 3, 2, 2, 2, 181, 184, 5, 6, 4, 2, 182, 184,
+	5, 4, 3, 2, 183, 181, 3, 2, 2, 2, 183, 182, 3, 2, 2, 2, 184, 27, 3, 2,
+	2, 2, 20, 34, 48, 58, 69, 71, 88, 99, 101, 112, 123, 136, 141, 151, 156,
+	164, 169, 178, 183,
 }
 var deserializer = antlr.NewATNDeserializer(nil)
 var deserializedATN = deserializer.DeserializeFromUInt16(parserATN)
 
 var literalNames = []string{
-	"", "'!'", "'('", "')'", "','", "'or'", "'and'", "'>'", "'>='", "'<'",
-	"'<='", "'='", "'!='", "'in'", "'not in'", "'*'", "'%'", "'/'", "'+'",
-	"'-'",
+	"", "'!'", "'('", "')'", "','", "'['", "']'", "'{'", "'}'", "':'", "'or'",
+	"'and'", "'>'", "'>='", "'<'", "'<='", "'='", "'!='", "'in'", "'not in'",
+	"'**'", "'*'", "'%'", "'/'", "'+'", "'-'",
 }
 var symbolicNames = []string{
-	"", "", "", "", "", "OR", "AND", "GT", "GTE", "LT", "LTE", "EQ", "NEQ",
-	"IN", "NOTIN", "MUL", "MOD", "DIV", "ADD", "SUB", "INT", "FLOAT", "BOOLEAN",
-	"STRING", "VAR", "IDENTIFIER", "WS",
+	"", "", "", "", "", "", "", "", "", "", "OR", "AND", "GT", "GTE", "LT",
+	"LTE", "EQ", "NEQ", "IN", "NOTIN", "POW", "MUL", "MOD", "DIV", "ADD", "SUB",
+	"INT", "FLOAT", "BOOLEAN", "STRING", "VAR", "IDENTIFIER", "WS",
 }
 
 var ruleNames = []string{
 	"start", "boolExpression", "expression", "number", "stringList", "numberList",
-	"function", "args", "arg",
+	"listLiteral", "mapLiteral", "pair", "element", "function", "args", "arg",
 }
 var decisionToDFA = make([]*antlr.DFA, len(deserializedATN.DecisionToState))
 
@@ -126,28 +151,34 @@ const (
 	ExprParserT__1       = 2
 	ExprParserT__2       = 3
 	ExprParserT__3       = 4
-	ExprParserOR         = 5
-	ExprParserAND        = 6
-	ExprParserGT         = 7
-	ExprParserGTE        = 8
-	ExprParserLT         = 9
-	ExprParserLTE        = 10
-	ExprParserEQ         = 11
-	ExprParserNEQ        = 12
-	ExprParserIN         = 13
-	ExprParserNOTIN      = 14
-	ExprParserMUL        = 15
-	ExprParserMOD        = 16
-	ExprParserDIV        = 17
-	ExprParserADD        = 18
-	ExprParserSUB        = 19
-	ExprParserINT        = 20
-	ExprParserFLOAT      = 21
-	ExprParserBOOLEAN    = 22
-	ExprParserSTRING     = 23
-	ExprParserVAR        = 24
-	ExprParserIDENTIFIER = 25
-	ExprParserWS         = 26
+	ExprParserT__4       = 5
+	ExprParserT__5       = 6
+	ExprParserT__6       = 7
+	ExprParserT__7       = 8
+	ExprParserT__8       = 9
+	ExprParserOR         = 10
+	ExprParserAND        = 11
+	ExprParserGT         = 12
+	ExprParserGTE        = 13
+	ExprParserLT         = 14
+	ExprParserLTE        = 15
+	ExprParserEQ         = 16
+	ExprParserNEQ        = 17
+	ExprParserIN         = 18
+	ExprParserNOTIN      = 19
+	ExprParserPOW        = 20
+	ExprParserMUL        = 21
+	ExprParserMOD        = 22
+	ExprParserDIV        = 23
+	ExprParserADD        = 24
+	ExprParserSUB        = 25
+	ExprParserINT        = 26
+	ExprParserFLOAT      = 27
+	ExprParserBOOLEAN    = 28
+	ExprParserSTRING     = 29
+	ExprParserVAR        = 30
+	ExprParserIDENTIFIER = 31
+	ExprParserWS         = 32
 )
 
 // ExprParser rules.
@@ -158,9 +189,13 @@ const (
 	ExprParserRULE_number         = 3
 	ExprParserRULE_stringList     = 4
 	ExprParserRULE_numberList     = 5
-	ExprParserRULE_function       = 6
-	ExprParserRULE_args           = 7
-	ExprParserRULE_arg            = 8
+	ExprParserRULE_listLiteral    = 6
+	ExprParserRULE_mapLiteral     = 7
+	ExprParserRULE_pair           = 8
+	ExprParserRULE_element        = 9
+	ExprParserRULE_function       = 10
+	ExprParserRULE_args           = 11
+	ExprParserRULE_arg            = 12
 )
 
 // IStartContext is an interface to support dynamic dispatch.
@@ -201,20 +236,30 @@ func NewStartContext(parser antlr.Parser, parent antlr.ParserRuleContext, invoki
 
 func (s *StartContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *StartContext) BoolExpression() IBoolExpressionContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*IBoolExpressionContext)(nil)).Elem(), 0)
+func (s *StartContext) Expression() IExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IExpressionContext)(nil)).Elem(), 0)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(IBoolExpressionContext)
+	return t.(IExpressionContext)
 }
 
 func (s *StartContext) EOF() antlr.TerminalNode {
 	return s.GetToken(ExprParserEOF, 0)
 }
 
+func (s *StartContext) BoolExpression() IBoolExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IBoolExpressionContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IBoolExpressionContext)
+}
+
 func (s *StartContext) GetRuleContext() antlr.RuleContext {
 	return s
 }
@@ -255,14 +300,31 @@ func (p *ExprParser) Start() (localctx IStartContext) {
 		}
 	}()
 
-	p.EnterOuterAlt(localctx, 1)
-	{
-		p.SetState(18)
-		p.boolExpression(0)
-	}
-	{
-		p.SetState(19)
-		p.Match(ExprParserEOF)
+	p.SetState(32)
+	p.GetErrorHandler().Sync(p)
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 0, p.GetParserRuleContext()) {
+	case 1:
+		p.EnterOuterAlt(localctx, 1)
+		{
+			p.SetState(26)
+			p.expression(0)
+		}
+		{
+			p.SetState(27)
+			p.Match(ExprParserEOF)
+		}
+
+	case 2:
+		p.EnterOuterAlt(localctx, 2)
+		{
+			p.SetState(29)
+			p.boolExpression(0)
+		}
+		{
+			p.SetState(30)
+			p.Match(ExprParserEOF)
+		}
+
 	}
 
 	return localctx
@@ -544,8 +606,21 @@ func (s *InContext) GetRuleContext() antlr.RuleContext {
 	return s
 }
 
-func (s *InContext) Expression() IExpressionContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*IExpressionContext)(nil)).Elem(), 0)
+func (s *InContext) AllExpression() []IExpressionContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IExpressionContext)(nil)).Elem())
+	var tst = make([]IExpressionContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IExpressionContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *InContext) Expression(i int) IExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IExpressionContext)(nil)).Elem(), i)
 
 	if t == nil {
 		return nil
@@ -908,20 +983,20 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 	var _alt int
 
 	p.EnterOuterAlt(localctx, 1)
-	p.SetState(42)
+	p.SetState(56)
 	p.GetErrorHandler().Sync(p)
-	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 1, p.GetParserRuleContext()) {
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 2, p.GetParserRuleContext()) {
 	case 1:
 		localctx = NewNotContext(p, localctx)
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 
 		{
-			p.SetState(22)
+			p.SetState(35)
 			p.Match(ExprParserT__0)
 		}
 		{
-			p.SetState(23)
+			p.SetState(36)
 			p.boolExpression(11)
 		}
 
@@ -930,11 +1005,11 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(24)
+			p.SetState(37)
 			p.expression(0)
 		}
 		{
-			p.SetState(25)
+			p.SetState(38)
 
 			var _lt = p.GetTokenStream().LT(1)
 
@@ -952,7 +1027,7 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 			}
 		}
 		{
-			p.SetState(26)
+			p.SetState(39)
 			p.expression(0)
 		}
 
@@ -961,11 +1036,11 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(28)
+			p.SetState(41)
 			p.expression(0)
 		}
 		{
-			p.SetState(29)
+			p.SetState(42)
 
 			var _lt = p.GetTokenStream().LT(1)
 
@@ -982,21 +1057,27 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 				p.Consume()
 			}
 		}
-		p.SetState(32)
+		p.SetState(46)
 		p.GetErrorHandler().Sync(p)
-		switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 0, p.GetParserRuleContext()) {
+		switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 1, p.GetParserRuleContext()) {
 		case 1:
 			{
-				p.SetState(30)
+				p.SetState(43)
 				p.StringList()
 			}
 
 		case 2:
 			{
-				p.SetState(31)
+				p.SetState(44)
 				p.NumberList()
 			}
 
+		case 3:
+			{
+				p.SetState(45)
+				p.expression(0)
+			}
+
 		}
 
 	case 4:
@@ -1004,7 +1085,7 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(34)
+			p.SetState(48)
 			p.Match(ExprParserBOOLEAN)
 		}
 
@@ -1013,7 +1094,7 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(35)
+			p.SetState(49)
 			p.Function()
 		}
 
@@ -1022,15 +1103,15 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(36)
+			p.SetState(50)
 			p.Match(ExprParserT__1)
 		}
 		{
-			p.SetState(37)
+			p.SetState(51)
 			p.boolExpression(0)
 		}
 		{
-			p.SetState(38)
+			p.SetState(52)
 			p.Match(ExprParserT__2)
 		}
 
@@ -1039,7 +1120,7 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(40)
+			p.SetState(54)
 			p.Match(ExprParserVAR)
 		}
 
@@ -1048,15 +1129,15 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(41)
+			p.SetState(55)
 			p.Match(ExprParserIDENTIFIER)
 		}
 
 	}
 	p.GetParserRuleContext().SetStop(p.GetTokenStream().LT(-1))
-	p.SetState(55)
+	p.SetState(69)
 	p.GetErrorHandler().Sync(p)
-	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 3, p.GetParserRuleContext())
+	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 4, p.GetParserRuleContext())
 
 	for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
 		if _alt == 1 {
@@ -1064,19 +1145,19 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 				p.TriggerExitRuleEvent()
 			}
 			_prevctx = localctx
-			p.SetState(53)
+			p.SetState(67)
 			p.GetErrorHandler().Sync(p)
-			switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 2, p.GetParserRuleContext()) {
+			switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 3, p.GetParserRuleContext()) {
 			case 1:
 				localctx = NewBoolCompareContext(p, NewBoolExpressionContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, ExprParserRULE_boolExpression)
-				p.SetState(44)
+				p.SetState(58)
 
 				if !(p.Precpred(p.GetParserRuleContext(), 10)) {
 					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 10)", ""))
 				}
 				{
-					p.SetState(45)
+					p.SetState(59)
 
 					var _lt = p.GetTokenStream().LT(1)
 
@@ -1094,50 +1175,50 @@ func (p *ExprParser) boolExpression(_p int) (localctx IBoolExpressionContext) {
 					}
 				}
 				{
-					p.SetState(46)
+					p.SetState(60)
 					p.boolExpression(11)
 				}
 
 			case 2:
 				localctx = NewAndContext(p, NewBoolExpressionContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, ExprParserRULE_boolExpression)
-				p.SetState(47)
+				p.SetState(61)
 
 				if !(p.Precpred(p.GetParserRuleContext(), 7)) {
 					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 7)", ""))
 				}
 				{
-					p.SetState(48)
+					p.SetState(62)
 					p.Match(ExprParserAND)
 				}
 				{
-					p.SetState(49)
+					p.SetState(63)
 					p.boolExpression(8)
 				}
 
 			case 3:
 				localctx = NewOrContext(p, NewBoolExpressionContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, ExprParserRULE_boolExpression)
-				p.SetState(50)
+				p.SetState(64)
 
 				if !(p.Precpred(p.GetParserRuleContext(), 6)) {
 					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 6)", ""))
 				}
 				{
-					p.SetState(51)
+					p.SetState(65)
 					p.Match(ExprParserOR)
 				}
 				{
-					p.SetState(52)
+					p.SetState(66)
 					p.boolExpression(7)
 				}
 
 			}
 
 		}
-		p.SetState(57)
+		p.SetState(71)
 		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 3, p.GetParserRuleContext())
+		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 4, p.GetParserRuleContext())
 	}
 
 	return localctx
@@ -1193,6 +1274,46 @@ func (s *ExpressionContext) ToStringTree(ruleNames []string, recog antlr.Recogni
 	return antlr.TreesStringTree(s, ruleNames, recog)
 }
 
+type MapExpressionContext struct {
+	*ExpressionContext
+}
+
+func NewMapExpressionContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *MapExpressionContext {
+	var p = new(MapExpressionContext)
+
+	p.ExpressionContext = NewEmptyExpressionContext()
+	p.parser = parser
+	p.CopyFrom(ctx.(*ExpressionContext))
+
+	return p
+}
+
+func (s *MapExpressionContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *MapExpressionContext) MapLiteral() IMapLiteralContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IMapLiteralContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IMapLiteralContext)
+}
+
+func (s *MapExpressionContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.EnterMapExpression(s)
+	}
+}
+
+func (s *MapExpressionContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.ExitMapExpression(s)
+	}
+}
+
 type BracketContext struct {
 	*ExpressionContext
 }
@@ -1355,6 +1476,10 @@ func (s *MulDivContext) DIV() antlr.TerminalNode {
 	return s.GetToken(ExprParserDIV, 0)
 }
 
+func (s *MulDivContext) MOD() antlr.TerminalNode {
+	return s.GetToken(ExprParserMOD, 0)
+}
+
 func (s *MulDivContext) EnterRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(ExprListener); ok {
 		listenerT.EnterMulDiv(s)
@@ -1433,6 +1558,108 @@ func (s *AddSubContext) ExitRule(listener antlr.ParseTreeListener) {
 	}
 }
 
+type ListExpressionContext struct {
+	*ExpressionContext
+}
+
+func NewListExpressionContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *ListExpressionContext {
+	var p = new(ListExpressionContext)
+
+	p.ExpressionContext = NewEmptyExpressionContext()
+	p.parser = parser
+	p.CopyFrom(ctx.(*ExpressionContext))
+
+	return p
+}
+
+func (s *ListExpressionContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *ListExpressionContext) ListLiteral() IListLiteralContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IListLiteralContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IListLiteralContext)
+}
+
+func (s *ListExpressionContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.EnterListExpression(s)
+	}
+}
+
+func (s *ListExpressionContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.ExitListExpression(s)
+	}
+}
+
+type PowContext struct {
+	*ExpressionContext
+	op antlr.Token
+}
+
+func NewPowContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *PowContext {
+	var p = new(PowContext)
+
+	p.ExpressionContext = NewEmptyExpressionContext()
+	p.parser = parser
+	p.CopyFrom(ctx.(*ExpressionContext))
+
+	return p
+}
+
+func (s *PowContext) GetOp() antlr.Token { return s.op }
+
+func (s *PowContext) SetOp(v antlr.Token) { s.op = v }
+
+func (s *PowContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *PowContext) AllExpression() []IExpressionContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IExpressionContext)(nil)).Elem())
+	var tst = make([]IExpressionContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IExpressionContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *PowContext) Expression(i int) IExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IExpressionContext)(nil)).Elem(), i)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExpressionContext)
+}
+
+func (s *PowContext) POW() antlr.TerminalNode {
+	return s.GetToken(ExprParserPOW, 0)
+}
+
+func (s *PowContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.EnterPow(s)
+	}
+}
+
+func (s *PowContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.ExitPow(s)
+	}
+}
+
 type ExpressionFunctionContext struct {
 	*ExpressionContext
 }
@@ -1620,21 +1847,21 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 	var _alt int
 
 	p.EnterOuterAlt(localctx, 1)
-	p.SetState(70)
+	p.SetState(86)
 	p.GetErrorHandler().Sync(p)
-	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 4, p.GetParserRuleContext()) {
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 5, p.GetParserRuleContext()) {
 	case 1:
 		localctx = NewSubExpressionContext(p, localctx)
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 
 		{
-			p.SetState(59)
+			p.SetState(73)
 			p.Match(ExprParserSUB)
 		}
 		{
-			p.SetState(60)
-			p.expression(8)
+			p.SetState(74)
+			p.expression(10)
 		}
 
 	case 2:
@@ -1642,7 +1869,7 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(61)
+			p.SetState(75)
 			p.Match(ExprParserSTRING)
 		}
 
@@ -1651,7 +1878,7 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(62)
+			p.SetState(76)
 			p.Match(ExprParserIDENTIFIER)
 		}
 
@@ -1660,7 +1887,7 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(63)
+			p.SetState(77)
 			p.Number()
 		}
 
@@ -1669,7 +1896,7 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(64)
+			p.SetState(78)
 			p.Match(ExprParserVAR)
 		}
 
@@ -1678,7 +1905,7 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(65)
+			p.SetState(79)
 			p.Function()
 		}
 
@@ -1687,23 +1914,41 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(66)
+			p.SetState(80)
 			p.Match(ExprParserT__1)
 		}
 		{
-			p.SetState(67)
+			p.SetState(81)
 			p.expression(0)
 		}
 		{
-			p.SetState(68)
+			p.SetState(82)
 			p.Match(ExprParserT__2)
 		}
 
+	case 8:
+		localctx = NewListExpressionContext(p, localctx)
+		p.SetParserRuleContext(localctx)
+		_prevctx = localctx
+		{
+			p.SetState(84)
+			p.ListLiteral()
+		}
+
+	case 9:
+		localctx = NewMapExpressionContext(p, localctx)
+		p.SetParserRuleContext(localctx)
+		_prevctx = localctx
+		{
+			p.SetState(85)
+			p.MapLiteral()
+		}
+
 	}
 	p.GetParserRuleContext().SetStop(p.GetTokenStream().LT(-1))
-	p.SetState(80)
+	p.SetState(99)
 	p.GetErrorHandler().Sync(p)
-	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 6, p.GetParserRuleContext())
+	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 7, p.GetParserRuleContext())
 
 	for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
 		if _alt == 1 {
@@ -1711,19 +1956,39 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 				p.TriggerExitRuleEvent()
 			}
 			_prevctx = localctx
-			p.SetState(78)
+			p.SetState(97)
 			p.GetErrorHandler().Sync(p)
-			switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 5, p.GetParserRuleContext()) {
+			switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 6, p.GetParserRuleContext()) {
 			case 1:
+				localctx = NewPowContext(p, NewExpressionContext(p, _parentctx, _parentState))
+				p.PushNewRecursionContext(localctx, _startState, ExprParserRULE_expression)
+				p.SetState(88)
+
+				if !(p.Precpred(p.GetParserRuleContext(), 12)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 12)", ""))
+				}
+				{
+					p.SetState(89)
+
+					var _m = p.Match(ExprParserPOW)
+
+					localctx.(*PowContext).op = _m
+				}
+				{
+					p.SetState(90)
+					p.expression(12)
+				}
+
+			case 2:
 				localctx = NewMulDivContext(p, NewExpressionContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, ExprParserRULE_expression)
-				p.SetState(72)
+				p.SetState(91)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 9)) {
-					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 9)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 11)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 11)", ""))
 				}
 				{
-					p.SetState(73)
+					p.SetState(92)
 
 					var _lt = p.GetTokenStream().LT(1)
 
@@ -1731,7 +1996,7 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 
 					_la = p.GetTokenStream().LA(1)
 
-					if !(_la == ExprParserMUL || _la == ExprParserDIV) {
+					if !(((_la)&-(0x1f+1)) == 0 && ((1<<uint(_la))&((1<<ExprParserMUL)|(1<<ExprParserMOD)|(1<<ExprParserDIV))) != 0) {
 						var _ri = p.GetErrorHandler().RecoverInline(p)
 
 						localctx.(*MulDivContext).op = _ri
@@ -1741,20 +2006,20 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 					}
 				}
 				{
-					p.SetState(74)
-					p.expression(10)
+					p.SetState(93)
+					p.expression(12)
 				}
 
-			case 2:
+			case 3:
 				localctx = NewAddSubContext(p, NewExpressionContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, ExprParserRULE_expression)
-				p.SetState(75)
+				p.SetState(94)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 7)) {
-					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 7)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 9)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 9)", ""))
 				}
 				{
-					p.SetState(76)
+					p.SetState(95)
 
 					var _lt = p.GetTokenStream().LT(1)
 
@@ -1772,16 +2037,16 @@ func (p *ExprParser) expression(_p int) (localctx IExpressionContext) {
 					}
 				}
 				{
-					p.SetState(77)
-					p.expression(8)
+					p.SetState(96)
+					p.expression(10)
 				}
 
 			}
 
 		}
-		p.SetState(82)
+		p.SetState(101)
 		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 6, p.GetParserRuleContext())
+		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 7, p.GetParserRuleContext())
 	}
 
 	return localctx
@@ -1876,7 +2141,7 @@ func (p *ExprParser) Number() (localctx INumberContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(83)
+		p.SetState(102)
 		_la = p.GetTokenStream().LA(1)
 
 		if !(_la == ExprParserINT || _la == ExprParserFLOAT) {
@@ -1979,33 +2244,33 @@ func (p *ExprParser) StringList() (localctx IStringListContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(85)
+		p.SetState(104)
 		p.Match(ExprParserT__1)
 	}
 	{
-		p.SetState(86)
+		p.SetState(105)
 		p.Match(ExprParserSTRING)
 	}
-	p.SetState(91)
+	p.SetState(110)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	for _la == ExprParserT__3 {
 		{
-			p.SetState(87)
+			p.SetState(106)
 			p.Match(ExprParserT__3)
 		}
 		{
-			p.SetState(88)
+			p.SetState(107)
 			p.Match(ExprParserSTRING)
 		}
 
-		p.SetState(93)
+		p.SetState(112)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 	}
 	{
-		p.SetState(94)
+		p.SetState(113)
 		p.Match(ExprParserT__2)
 	}
 
@@ -2116,98 +2381,649 @@ func (p *ExprParser) NumberList() (localctx INumberListContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(96)
+		p.SetState(115)
 		p.Match(ExprParserT__1)
 	}
 	{
-		p.SetState(97)
+		p.SetState(116)
 		p.Number()
 	}
-	p.SetState(102)
+	p.SetState(121)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	for _la == ExprParserT__3 {
 		{
-			p.SetState(98)
+			p.SetState(117)
 			p.Match(ExprParserT__3)
 		}
 		{
-			p.SetState(99)
+			p.SetState(118)
 			p.Number()
 		}
 
-		p.SetState(104)
+		p.SetState(123)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 	}
 	{
-		p.SetState(105)
+		p.SetState(124)
 		p.Match(ExprParserT__2)
 	}
 
 	return localctx
 }
 
-// IFunctionContext is an interface to support dynamic dispatch.
-type IFunctionContext interface {
+// IListLiteralContext is an interface to support dynamic dispatch.
+type IListLiteralContext interface {
 	antlr.ParserRuleContext
 
 	// GetParser returns the parser.
 	GetParser() antlr.Parser
 
-	// GetName returns the name token.
-	GetName() antlr.Token
-
-	// SetName sets the name token.
-	SetName(antlr.Token)
-
-	// GetFnargs returns the fnargs rule contexts.
-	GetFnargs() IArgsContext
-
-	// SetFnargs sets the fnargs rule contexts.
-	SetFnargs(IArgsContext)
-
-	// IsFunctionContext differentiates from other interfaces.
-	IsFunctionContext()
+	// IsListLiteralContext differentiates from other interfaces.
+	IsListLiteralContext()
 }
 
-type FunctionContext struct {
+type ListLiteralContext struct {
 	*antlr.BaseParserRuleContext
 	parser antlr.Parser
-	name   antlr.Token
-	fnargs IArgsContext
 }
 
-func NewEmptyFunctionContext() *FunctionContext {
-	var p = new(FunctionContext)
+func NewEmptyListLiteralContext() *ListLiteralContext {
+	var p = new(ListLiteralContext)
 	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
-	p.RuleIndex = ExprParserRULE_function
+	p.RuleIndex = ExprParserRULE_listLiteral
 	return p
 }
 
-func (*FunctionContext) IsFunctionContext() {}
+func (*ListLiteralContext) IsListLiteralContext() {}
 
-func NewFunctionContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *FunctionContext {
-	var p = new(FunctionContext)
+func NewListLiteralContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *ListLiteralContext {
+	var p = new(ListLiteralContext)
 
 	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
 
 	p.parser = parser
-	p.RuleIndex = ExprParserRULE_function
+	p.RuleIndex = ExprParserRULE_listLiteral
 
 	return p
 }
 
-func (s *FunctionContext) GetParser() antlr.Parser { return s.parser }
+func (s *ListLiteralContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *FunctionContext) GetName() antlr.Token { return s.name }
+func (s *ListLiteralContext) AllElement() []IElementContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IElementContext)(nil)).Elem())
+	var tst = make([]IElementContext, len(ts))
 
-func (s *FunctionContext) SetName(v antlr.Token) { s.name = v }
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IElementContext)
+		}
+	}
 
-func (s *FunctionContext) GetFnargs() IArgsContext { return s.fnargs }
+	return tst
+}
 
-func (s *FunctionContext) SetFnargs(v IArgsContext) { s.fnargs = v }
+func (s *ListLiteralContext) Element(i int) IElementContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IElementContext)(nil)).Elem(), i)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IElementContext)
+}
+
+func (s *ListLiteralContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *ListLiteralContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+	return antlr.TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *ListLiteralContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.EnterListLiteral(s)
+	}
+}
+
+func (s *ListLiteralContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.ExitListLiteral(s)
+	}
+}
+
+func (p *ExprParser) ListLiteral() (localctx IListLiteralContext) {
+	localctx = NewListLiteralContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 12, ExprParserRULE_listLiteral)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(antlr.RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.SetState(139)
+	p.GetErrorHandler().Sync(p)
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 11, p.GetParserRuleContext()) {
+	case 1:
+		p.EnterOuterAlt(localctx, 1)
+		{
+			p.SetState(126)
+			p.Match(ExprParserT__4)
+		}
+		{
+			p.SetState(127)
+			p.Match(ExprParserT__5)
+		}
+
+	case 2:
+		p.EnterOuterAlt(localctx, 2)
+		{
+			p.SetState(128)
+			p.Match(ExprParserT__4)
+		}
+		{
+			p.SetState(129)
+			p.Element()
+		}
+		p.SetState(134)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+
+		for _la == ExprParserT__3 {
+			{
+				p.SetState(130)
+				p.Match(ExprParserT__3)
+			}
+			{
+				p.SetState(131)
+				p.Element()
+			}
+
+			p.SetState(136)
+			p.GetErrorHandler().Sync(p)
+			_la = p.GetTokenStream().LA(1)
+		}
+		{
+			p.SetState(137)
+			p.Match(ExprParserT__5)
+		}
+
+	}
+
+	return localctx
+}
+
+// IMapLiteralContext is an interface to support dynamic dispatch.
+type IMapLiteralContext interface {
+	antlr.ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() antlr.Parser
+
+	// IsMapLiteralContext differentiates from other interfaces.
+	IsMapLiteralContext()
+}
+
+type MapLiteralContext struct {
+	*antlr.BaseParserRuleContext
+	parser antlr.Parser
+}
+
+func NewEmptyMapLiteralContext() *MapLiteralContext {
+	var p = new(MapLiteralContext)
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = ExprParserRULE_mapLiteral
+	return p
+}
+
+func (*MapLiteralContext) IsMapLiteralContext() {}
+
+func NewMapLiteralContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *MapLiteralContext {
+	var p = new(MapLiteralContext)
+
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = ExprParserRULE_mapLiteral
+
+	return p
+}
+
+func (s *MapLiteralContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *MapLiteralContext) AllPair() []IPairContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IPairContext)(nil)).Elem())
+	var tst = make([]IPairContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IPairContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *MapLiteralContext) Pair(i int) IPairContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IPairContext)(nil)).Elem(), i)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IPairContext)
+}
+
+func (s *MapLiteralContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *MapLiteralContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+	return antlr.TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *MapLiteralContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.EnterMapLiteral(s)
+	}
+}
+
+func (s *MapLiteralContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.ExitMapLiteral(s)
+	}
+}
+
+func (p *ExprParser) MapLiteral() (localctx IMapLiteralContext) {
+	localctx = NewMapLiteralContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 14, ExprParserRULE_mapLiteral)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(antlr.RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.SetState(154)
+	p.GetErrorHandler().Sync(p)
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 13, p.GetParserRuleContext()) {
+	case 1:
+		p.EnterOuterAlt(localctx, 1)
+		{
+			p.SetState(141)
+			p.Match(ExprParserT__6)
+		}
+		{
+			p.SetState(142)
+			p.Match(ExprParserT__7)
+		}
+
+	case 2:
+		p.EnterOuterAlt(localctx, 2)
+		{
+			p.SetState(143)
+			p.Match(ExprParserT__6)
+		}
+		{
+			p.SetState(144)
+			p.Pair()
+		}
+		p.SetState(149)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+
+		for _la == ExprParserT__3 {
+			{
+				p.SetState(145)
+				p.Match(ExprParserT__3)
+			}
+			{
+				p.SetState(146)
+				p.Pair()
+			}
+
+			p.SetState(151)
+			p.GetErrorHandler().Sync(p)
+			_la = p.GetTokenStream().LA(1)
+		}
+		{
+			p.SetState(152)
+			p.Match(ExprParserT__7)
+		}
+
+	}
+
+	return localctx
+}
+
+// IPairContext is an interface to support dynamic dispatch.
+type IPairContext interface {
+	antlr.ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() antlr.Parser
+
+	// GetKey returns the key token.
+	GetKey() antlr.Token
+
+	// SetKey sets the key token.
+	SetKey(antlr.Token)
+
+	// IsPairContext differentiates from other interfaces.
+	IsPairContext()
+}
+
+type PairContext struct {
+	*antlr.BaseParserRuleContext
+	parser antlr.Parser
+	key    antlr.Token
+}
+
+func NewEmptyPairContext() *PairContext {
+	var p = new(PairContext)
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = ExprParserRULE_pair
+	return p
+}
+
+func (*PairContext) IsPairContext() {}
+
+func NewPairContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *PairContext {
+	var p = new(PairContext)
+
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = ExprParserRULE_pair
+
+	return p
+}
+
+func (s *PairContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *PairContext) GetKey() antlr.Token { return s.key }
+
+func (s *PairContext) SetKey(v antlr.Token) { s.key = v }
+
+func (s *PairContext) Element() IElementContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IElementContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IElementContext)
+}
+
+func (s *PairContext) STRING() antlr.TerminalNode {
+	return s.GetToken(ExprParserSTRING, 0)
+}
+
+func (s *PairContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *PairContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+	return antlr.TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *PairContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.EnterPair(s)
+	}
+}
+
+func (s *PairContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.ExitPair(s)
+	}
+}
+
+func (p *ExprParser) Pair() (localctx IPairContext) {
+	localctx = NewPairContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 16, ExprParserRULE_pair)
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(antlr.RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.EnterOuterAlt(localctx, 1)
+	{
+		p.SetState(156)
+
+		var _m = p.Match(ExprParserSTRING)
+
+		localctx.(*PairContext).key = _m
+	}
+	{
+		p.SetState(157)
+		p.Match(ExprParserT__8)
+	}
+	{
+		p.SetState(158)
+		p.Element()
+	}
+
+	return localctx
+}
+
+// IElementContext is an interface to support dynamic dispatch.
+type IElementContext interface {
+	antlr.ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() antlr.Parser
+
+	// IsElementContext differentiates from other interfaces.
+	IsElementContext()
+}
+
+type ElementContext struct {
+	*antlr.BaseParserRuleContext
+	parser antlr.Parser
+}
+
+func NewEmptyElementContext() *ElementContext {
+	var p = new(ElementContext)
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = ExprParserRULE_element
+	return p
+}
+
+func (*ElementContext) IsElementContext() {}
+
+func NewElementContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *ElementContext {
+	var p = new(ElementContext)
+
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = ExprParserRULE_element
+
+	return p
+}
+
+func (s *ElementContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *ElementContext) Expression() IExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IExpressionContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExpressionContext)
+}
+
+func (s *ElementContext) BoolExpression() IBoolExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IBoolExpressionContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IBoolExpressionContext)
+}
+
+func (s *ElementContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *ElementContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+	return antlr.TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *ElementContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.EnterElement(s)
+	}
+}
+
+func (s *ElementContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ExprListener); ok {
+		listenerT.ExitElement(s)
+	}
+}
+
+func (p *ExprParser) Element() (localctx IElementContext) {
+	localctx = NewElementContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 18, ExprParserRULE_element)
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(antlr.RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.SetState(162)
+	p.GetErrorHandler().Sync(p)
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 14, p.GetParserRuleContext()) {
+	case 1:
+		p.EnterOuterAlt(localctx, 1)
+		{
+			p.SetState(160)
+			p.expression(0)
+		}
+
+	case 2:
+		p.EnterOuterAlt(localctx, 2)
+		{
+			p.SetState(161)
+			p.boolExpression(0)
+		}
+
+	}
+
+	return localctx
+}
+
+// IFunctionContext is an interface to support dynamic dispatch.
+type IFunctionContext interface {
+	antlr.ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() antlr.Parser
+
+	// GetName returns the name token.
+	GetName() antlr.Token
+
+	// SetName sets the name token.
+	SetName(antlr.Token)
+
+	// GetFnargs returns the fnargs rule contexts.
+	GetFnargs() IArgsContext
+
+	// SetFnargs sets the fnargs rule contexts.
+	SetFnargs(IArgsContext)
+
+	// IsFunctionContext differentiates from other interfaces.
+	IsFunctionContext()
+}
+
+type FunctionContext struct {
+	*antlr.BaseParserRuleContext
+	parser antlr.Parser
+	name   antlr.Token
+	fnargs IArgsContext
+}
+
+func NewEmptyFunctionContext() *FunctionContext {
+	var p = new(FunctionContext)
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = ExprParserRULE_function
+	return p
+}
+
+func (*FunctionContext) IsFunctionContext() {}
+
+func NewFunctionContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *FunctionContext {
+	var p = new(FunctionContext)
+
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = ExprParserRULE_function
+
+	return p
+}
+
+func (s *FunctionContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *FunctionContext) GetName() antlr.Token { return s.name }
+
+func (s *FunctionContext) SetName(v antlr.Token) { s.name = v }
+
+func (s *FunctionContext) GetFnargs() IArgsContext { return s.fnargs }
+
+func (s *FunctionContext) SetFnargs(v IArgsContext) { s.fnargs = v }
 
 func (s *FunctionContext) IDENTIFIER() antlr.TerminalNode {
 	return s.GetToken(ExprParserIDENTIFIER, 0)
@@ -2245,7 +3061,7 @@ func (s *FunctionContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *ExprParser) Function() (localctx IFunctionContext) {
 	localctx = NewFunctionContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 12, ExprParserRULE_function)
+	p.EnterRule(localctx, 20, ExprParserRULE_function)
 	var _la int
 
 	defer func() {
@@ -2266,23 +3082,23 @@ func (p *ExprParser) Function() (localctx IFunctionContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(107)
+		p.SetState(164)
 
 		var _m = p.Match(ExprParserIDENTIFIER)
 
 		localctx.(*FunctionContext).name = _m
 	}
 	{
-		p.SetState(108)
+		p.SetState(165)
 		p.Match(ExprParserT__1)
 	}
-	p.SetState(110)
+	p.SetState(167)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
-	if ((_la)&-(0x1f+1)) == 0 && ((1<<uint(_la))&((1<<ExprParserINT)|(1<<ExprParserFLOAT)|(1<<ExprParserBOOLEAN)|(1<<ExprParserSTRING)|(1<<ExprParserVAR))) != 0 {
+	if ((_la)&-(0x1f+1)) == 0 && ((1<<uint(_la))&((1<<ExprParserT__0)|(1<<ExprParserT__1)|(1<<ExprParserT__4)|(1<<ExprParserT__6)|(1<<ExprParserSUB)|(1<<ExprParserINT)|(1<<ExprParserFLOAT)|(1<<ExprParserBOOLEAN)|(1<<ExprParserSTRING)|(1<<ExprParserVAR)|(1<<ExprParserIDENTIFIER))) != 0 {
 		{
-			p.SetState(109)
+			p.SetState(166)
 
 			var _x = p.Args()
 
@@ -2291,7 +3107,7 @@ func (p *ExprParser) Function() (localctx IFunctionContext) {
 
 	}
 	{
-		p.SetState(112)
+		p.SetState(169)
 		p.Match(ExprParserT__2)
 	}
 
@@ -2381,7 +3197,7 @@ func (s *ArgsContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *ExprParser) Args() (localctx IArgsContext) {
 	localctx = NewArgsContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 14, ExprParserRULE_args)
+	p.EnterRule(localctx, 22, ExprParserRULE_args)
 	var _la int
 
 	defer func() {
@@ -2402,24 +3218,24 @@ func (p *ExprParser) Args() (localctx IArgsContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(114)
+		p.SetState(171)
 		p.Arg()
 	}
-	p.SetState(119)
+	p.SetState(176)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
 	for _la == ExprParserT__3 {
 		{
-			p.SetState(115)
+			p.SetState(172)
 			p.Match(ExprParserT__3)
 		}
 		{
-			p.SetState(116)
+			p.SetState(173)
 			p.Arg()
 		}
 
-		p.SetState(121)
+		p.SetState(178)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
 	}
@@ -2465,26 +3281,24 @@ func NewArgContext(parser antlr.Parser, parent antlr.ParserRuleContext, invoking
 
 func (s *ArgContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *ArgContext) VAR() antlr.TerminalNode {
-	return s.GetToken(ExprParserVAR, 0)
-}
-
-func (s *ArgContext) Number() INumberContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*INumberContext)(nil)).Elem(), 0)
+func (s *ArgContext) Expression() IExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IExpressionContext)(nil)).Elem(), 0)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(INumberContext)
+	return t.(IExpressionContext)
 }
 
-func (s *ArgContext) BOOLEAN() antlr.TerminalNode {
-	return s.GetToken(ExprParserBOOLEAN, 0)
-}
+func (s *ArgContext) BoolExpression() IBoolExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IBoolExpressionContext)(nil)).Elem(), 0)
 
-func (s *ArgContext) STRING() antlr.TerminalNode {
-	return s.GetToken(ExprParserSTRING, 0)
+	if t == nil {
+		return nil
+	}
+
+	return t.(IBoolExpressionContext)
 }
 
 func (s *ArgContext) GetRuleContext() antlr.RuleContext {
@@ -2509,7 +3323,7 @@ func (s *ArgContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *ExprParser) Arg() (localctx IArgContext) {
 	localctx = NewArgContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 16, ExprParserRULE_arg)
+	p.EnterRule(localctx, 24, ExprParserRULE_arg)
 
 	defer func() {
 		p.ExitRule()
@@ -2527,40 +3341,23 @@ func (p *ExprParser) Arg() (localctx IArgContext) {
 		}
 	}()
 
-	p.SetState(126)
+	p.SetState(181)
 	p.GetErrorHandler().Sync(p)
-
-	switch p.GetTokenStream().LA(1) {
-	case ExprParserVAR:
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 17, p.GetParserRuleContext()) {
+	case 1:
 		p.EnterOuterAlt(localctx, 1)
 		{
-			p.SetState(122)
-			p.Match(ExprParserVAR)
+			p.SetState(179)
+			p.expression(0)
 		}
 
-	case ExprParserINT, ExprParserFLOAT:
+	case 2:
 		p.EnterOuterAlt(localctx, 2)
 		{
-			p.SetState(123)
-			p.Number()
-		}
-
-	case ExprParserBOOLEAN:
-		p.EnterOuterAlt(localctx, 3)
-		{
-			p.SetState(124)
-			p.Match(ExprParserBOOLEAN)
-		}
-
-	case ExprParserSTRING:
-		p.EnterOuterAlt(localctx, 4)
-		{
-			p.SetState(125)
-			p.Match(ExprParserSTRING)
+			p.SetState(180)
+			p.boolExpression(0)
 		}
 
-	default:
-		panic(antlr.NewNoViableAltException(p, nil, nil, nil, nil, nil))
 	}
 
 	return localctx
@@ -2606,10 +3403,13 @@ func (p *ExprParser) BoolExpression_Sempred(localctx antlr.RuleContext, predInde
 func (p *ExprParser) Expression_Sempred(localctx antlr.RuleContext, predIndex int) bool {
 	switch predIndex {
 	case 3:
-		return p.Precpred(p.GetParserRuleContext(), 9)
+		return p.Precpred(p.GetParserRuleContext(), 12)
 
 	case 4:
-		return p.Precpred(p.GetParserRuleContext(), 7)
+		return p.Precpred(p.GetParserRuleContext(), 11)
+
+	case 5:
+		return p.Precpred(p.GetParserRuleContext(), 9)
 
 	default:
 		panic("No predicate with index: " + fmt.Sprint(predIndex))
@@ -1,6 +1,8 @@
 package expr
 
 import (
+	"context"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -111,6 +113,35 @@ func TestParseExpression(t *testing.T) {
 	}
 }
 
+func TestParseResultType(t *testing.T) {
+	cases := map[string]reflect.Type{
+		`$var`:               nil,
+		`-$var`:              nil,
+		`t_int() + 1`:        reflect.TypeOf(int64(0)),
+		`(t_int() * 1.5)`:    reflect.TypeOf(float64(0)),
+		`-t_float()`:         reflect.TypeOf(float64(0)),
+		`'a'`:                reflect.TypeOf(``),
+		`t_string()`:         reflect.TypeOf(``),
+		`t_bool()`:           reflect.TypeOf(false),
+		`1 > 2 and t_bool()`: reflect.TypeOf(false),
+	}
+	for input, typ := range cases {
+		p := NewParser()
+		_, err := p.ParseValueWithCache(input)
+		require.Nil(t, err, input)
+		require.Equal(t, typ, p.ResultType(), input)
+	}
+	invalids := []string{
+		`t_bool() + 1`,
+		`-t_bool()`,
+		`1 +`,
+	}
+	for _, v := range invalids {
+		_, err := NewParser().ParseValueWithCache(v)
+		require.NotNil(t, err, v)
+	}
+}
+
 func TestParseCompare(t *testing.T) {
 	invalids := []string{
 		`1>'a'`,
@@ -198,8 +229,16 @@ func TestParseFunctionArgs(t *testing.T) {
 		`testArgs(1, 1,1,1)`,
 		`testArgs(1, 1.0,true,'a', 1)`,
 		`testArgs(1.0, 1,true,'a')`,
-		`testArgs(-$a,$a,$a,$a)`,
 		`testArgs(!$a,$a,$a,$a)`,
+		`testArgs(t_float(),$a,$a,$a)`,
+		`testArgs(1 + 1.5,$a,$a,$a)`,
+		`testArgs($a,$a,$a,t_int())`,
+		`testArgs($a,$a,1 > 2 + 'a',$a)`,
+		`testArgs($a,$a,$a,toLower(1))`,
+		`testArgs($a,$a,$a,$a`,
+		`testArgs($a,$a,$a,$a * 2)`,
+		`testArgs($a,$a,$a,-$a)`,
+		`testArgs($a,$a,$a,(1+a)*3)`,
 	}
 	for _, v := range invalids {
 		p := NewParser()
@@ -211,6 +250,10 @@ func TestParseFunctionArgs(t *testing.T) {
 		`testArgs(1, 1.0,true,'a')`,
 		`testArgs(1, 1.0,true,'a')`,
 		`testArgs($a,$a,$a,$a)`,
+		`testArgs(-$a,$a,$a,$a)`,
+		`testArgs(t_int() * 2, t_float() / 2, !t_bool() and $b, concat(toLower($s), 'a'))`,
+		`testArgs(-(1 + 2) % 2, 2.0 ** 2, 1 > 2, t_string())`,
+		`testArgs($a, $a, $a in [1, 2], $a)`,
 	}
 	for _, v := range valids {
 		p := NewParser()
@@ -221,17 +264,35 @@ func TestParseFunctionArgs(t *testing.T) {
 
 func TestCheckFunction(t *testing.T) {
 	invalids := map[string]interface{}{
-		`k1`: 1,
-		`k2`: func(...interface{}) {},
-		`k3`: func(string, int) {},
-		`k4`: func() (string, int, error) { return ``, 0, nil },
-		`k5`: func() (error, int) { return nil, 0 },
-		`k6`: func() int { return 0 },
-		`k7`: func() (int, error) { return 0, nil },
-		`k8`: func() error { return nil },
+		`k1`:  1,
+		`k2`:  func(...interface{}) {},
+		`k3`:  func(string, int) {},
+		`k4`:  func() (string, int, error) { return ``, 0, nil },
+		`k5`:  func() (error, int) { return nil, 0 },
+		`k8`:  func() error { return nil },
+		`k9`:  func(string, context.Context) bool { return true },
+		`k10`: func(...chan int) bool { return true },
+		`k11`: func([]func()) bool { return true },
+		`k12`: func(map[int]string) bool { return true },
+		`k13`: func() chan int { return nil },
+		`k14`: func(error) bool { return true },
 	}
 	for k, v := range invalids {
 		_, _, err := checkFunction(k, v)
 		require.NotEqual(t, nil, err, k, v)
 	}
+	_, _, err := checkFunction(`ctx`, func(context.Context, string) bool { return true })
+	require.Nil(t, err)
+	valids := map[string]interface{}{
+		`v1`: func() int { return 0 },
+		`v2`: func() (int, error) { return 0, nil },
+		`v3`: func(int8, uint, float32, []string, map[string]int) []float32 { return nil },
+		`v4`: func(interface{}, ...[]interface{}) (map[string]interface{}, error) { return nil, nil },
+	}
+	for k, v := range valids {
+		_, _, err := checkFunction(k, v)
+		require.Nil(t, err, k)
+	}
+	_, _, err = checkFunction(`variadic`, func(context.Context, string, ...float64) bool { return true })
+	require.Nil(t, err)
 }
@@ -0,0 +1,48 @@
+package expr
+
+import (
+	"github.com/EchoUtopia/zerror"
+)
+
+// PartialEval binds known variables of program and folds everything it can,
+// the residual program references only the unknown variables.
+// identifiers are bound by known too, so expressions with identifiers can be evaluated once they are known.
+// when the result doesn't depend on the unknown variables, the residual program is a constant, see Program.Constant.
+// `false and $unknown` is false, and so is `$unknown > 1 and false`, errors of the dropped operand are not reported,
+// but `$unknown and false` is kept, as it fails if $unknown is missing or not bool, see optimizer.logic
+func PartialEval(program *Program, known map[string]interface{}) (*Program, error) {
+	o := &optimizer{known: known, bound: map[string]interface{}{}, getFunc: program.getFunc}
+	for name, v := range program.bound {
+		o.bound[name] = v
+	}
+	root := o.optimize(program.root)
+	code, k, err := compileNode(root, program.getFunc)
+	if err != nil {
+		return nil, err
+	}
+	residual := &Program{
+		expr:     formatNode(root),
+		root:     root,
+		code:     code,
+		kind:     k,
+		number:   isNumber(root),
+		evalOpts: program.evalOpts,
+	}
+	if len(o.bound) > 0 {
+		residual.bound = o.bound
+	}
+	return residual, nil
+}
+
+// getFunc looks up functions the program was compiled with, later changes of the Env don't affect it
+func (p *Program) getFunc(name string) (*function, error) {
+	if fn, ok := p.resolved[name]; ok {
+		return fn, nil
+	}
+	for _, fn := range p.code.funcs {
+		if fn.name == name {
+			return fn, nil
+		}
+	}
+	return nil, zerror.BadRequest.Errorf(`func: %s not found`, name)
+}
@@ -0,0 +1,138 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartialEval(t *testing.T) {
+	parser := NewParser()
+	require.Nil(t, parser.RegisterFunc(`year`, func(x interface{}) int {
+		return x.(time.Time).Year()
+	}))
+	known := map[string]interface{}{
+		`tenant`: `a`,
+		`region`: `eu`,
+		`limits`: []int{1, 2},
+		`user`:   map[string]interface{}{`tier`: `gold`, `age`: 20},
+		`t`:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	cases := []struct {
+		expr     string
+		residual string
+		vars     []string
+	}{
+		{`$tenant = 'a' and $x > 1`, `$x > 1`, []string{`x`}},
+		{`$tenant = 'b' and $x > 1`, `false`, nil},
+		{`$x > 1 and $tenant = 'b'`, `false`, nil},
+		{`$x and $tenant = 'b'`, `$x and false`, []string{`x`}},
+		{`$x > 1 or $user.tier in ['gold', 'vip']`, `true`, nil},
+		{`(3 + 2) * 2.0 = $n and startsWith($car, 'b')`, `10.0 = $n and startsWith($car, 'b')`, []string{`car`, `n`}},
+		{`region = 'eu' and $x`, `$x`, []string{`x`}},
+		{`$user.age + $x > $limits[1] * 10`, `20 + $x > 20`, []string{`x`}},
+		{`$x in $limits or !!($tenant != 'a')`, `$x in [1, 2]`, []string{`x`}},
+		{`concat($tenant, $y) = 'ab'`, `concat('a', $y) = 'ab'`, []string{`y`}},
+		{`year($t) > 2000 and $x`, `year($t) > 2000 and $x`, []string{`x`}},
+		{`$x and 1 / 0 = 1`, `$x and 1 / 0 = 1`, []string{`x`}},
+		{`$x > 1.0 / 0`, `$x > 1.0 / 0`, []string{`x`}},
+		{`$user.missing = 1 or $x`, `$user.missing = 1 or $x`, []string{`x`}},
+	}
+	for _, c := range cases {
+		program, err := parser.Compile(c.expr)
+		require.Nil(t, err, c.expr)
+		residual, err := PartialEval(program, known)
+		require.Nil(t, err, c.expr)
+		require.Equal(t, c.residual, residual.String(), c.expr)
+		require.Equal(t, c.vars, residual.Variables(), c.expr)
+	}
+
+	program, err := parser.Compile(`$tenant = 'a' and $x > 1`)
+	require.Nil(t, err)
+	residual, err := PartialEval(program, map[string]interface{}{`tenant`: `b`})
+	require.Nil(t, err)
+	result, ok := residual.Constant()
+	require.True(t, ok)
+	require.Equal(t, false, result)
+
+	residual, err = PartialEval(program, map[string]interface{}{`tenant`: `a`})
+	require.Nil(t, err)
+	_, ok = residual.Constant()
+	require.False(t, ok)
+	for _, x := range []int{1, 2} {
+		expect, err := program.Eval(map[string]interface{}{`tenant`: `a`, `x`: x})
+		require.Nil(t, err)
+		result, err := residual.Eval(map[string]interface{}{`x`: x})
+		require.Nil(t, err)
+		require.Equal(t, expect, result)
+	}
+
+	// bound variables are kept by residual programs of residual programs
+	program, err = parser.Compile(`year($t) > 2000 and $x and $y`)
+	require.Nil(t, err)
+	residual, err = PartialEval(program, known)
+	require.Nil(t, err)
+	residual, err = PartialEval(residual, map[string]interface{}{`x`: true})
+	require.Nil(t, err)
+	require.Equal(t, `year($t) > 2000 and $y`, residual.String())
+	result, err = residual.EvalValue(map[string]interface{}{`y`: true})
+	require.Nil(t, err)
+	require.Equal(t, true, result)
+}
+
+func TestFormatNode(t *testing.T) {
+	inputs := []string{
+		`$a and $b or !$c and ($d or $e)`,
+		`!($a = 1) and !$b = false`,
+		`(1 + 2) * 3 - -$a / (4 % $b) = 2 ** 3 ** 2`,
+		`(2 ** 3) ** 2 > -(2 ** 2) and (-$a) ** 2 > 1 - (2 - 3)`,
+		`$a.b[0].c in ['x', 'it\'s', 'a\\b'] and 'k' not in {'k': [1.5, true], 'j': {}}`,
+		`$a in ('a', 'b') and 1 not in (1, 2.5) and $b in $c`,
+		`f(g($a, 1 + 2), $b > 1, []) = 'x'`,
+		`($a = $b) = ($c > 1)`,
+		`-1 - 1 > -0.5 and ident`,
+	}
+	parser := NewParser()
+	for _, name := range []string{`f`, `g`} {
+		require.Nil(t, parser.RegisterFunc(name, func(...interface{}) string { return `` }))
+	}
+	for _, input := range inputs {
+		tree, err := parser.Parse(input)
+		require.Nil(t, err, input)
+		n := tree.root
+		formatted := formatNode(n)
+		tree, err = parser.Parse(formatted)
+		require.Nil(t, err, formatted)
+		require.Equal(t, formatted, formatNode(tree.root), input)
+		require.Equal(t, stripSpans(n), stripSpans(tree.root), input)
+	}
+	require.Equal(t, `$a and $b or !$c and ($d or $e)`, formatNode(lowerString(t, `$a and $b or !$c and ($d or $e)`)))
+	require.Equal(t, `-(2 ** 2) < 1`, formatNode(lowerString(t, `-(2 ** 2) < 1`)))
+	require.Equal(t, `[1, 'a', 2.0]`, formatNode(&literalNode{val: listValue([]interface{}{1, `a`, 2.0})}))
+	_, ok := formatValue(listValue([]interface{}{time.Now()}))
+	require.False(t, ok)
+}
+
+func lowerString(t *testing.T, input string) node {
+	tree, err := NewParser().Parse(input)
+	require.Nil(t, err, input)
+	return tree.root
+}
+
+// stripSpans formats n with brackets around every node, so nodes of different shapes never match
+func stripSpans(n node) string {
+	switch n := n.(type) {
+	case *unaryNode:
+		return `(` + n.op.String() + stripSpans(n.x) + `)`
+	case *binaryNode:
+		return `(` + stripSpans(n.x) + ` ` + n.op.String() + ` ` + stripSpans(n.y) + `)`
+	case *inNode:
+		op := ` in `
+		if n.not {
+			op = ` not in `
+		}
+		return `(` + stripSpans(n.x) + op + stripSpans(n.y) + `)`
+	}
+	return formatNode(n)
+}
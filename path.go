@@ -0,0 +1,159 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// pathStep is a field or an index after the name of a variable, like .address or [0]
+type pathStep struct {
+	field   string
+	index   int
+	isIndex bool
+}
+
+func (s pathStep) String() string {
+	if s.isIndex {
+		return `[` + strconv.Itoa(s.index) + `]`
+	}
+	return `.` + s.field
+}
+
+// splitVar splits the text of a VAR token without `$`, like order.items[0].sku, into its name and path,
+// it fails if an index is out of range
+func splitVar(text string) (string, []pathStep, error) {
+	end := strings.IndexAny(text, `.[`)
+	if end < 0 {
+		return text, nil, nil
+	}
+	name, rest := text[:end], text[end:]
+	var path []pathStep
+	for rest != `` {
+		if rest[0] == '[' {
+			closing := strings.IndexByte(rest, ']')
+			index, err := strconv.Atoi(rest[1:closing])
+			if err != nil {
+				return ``, nil, zerror.BadRequest.Errorf(`invalid index: %s`, rest[1:closing])
+			}
+			path = append(path, pathStep{index: index, isIndex: true})
+			rest = rest[closing+1:]
+			continue
+		}
+		end := strings.IndexAny(rest[1:], `.[`)
+		if end < 0 {
+			end = len(rest) - 1
+		}
+		path = append(path, pathStep{field: rest[1 : end+1]})
+		rest = rest[end+1:]
+	}
+	return name, path, nil
+}
+
+func pathString(name string, path []pathStep) string {
+	var b strings.Builder
+	b.WriteString(`$`)
+	b.WriteString(name)
+	for _, step := range path {
+		b.WriteString(step.String())
+	}
+	return b.String()
+}
+
+// walkPath gets the value at path in v, which can be nested maps with string keys, structs, slices and arrays
+func walkPath(name string, v interface{}, path []pathStep) (interface{}, error) {
+	for i, step := range path {
+		// fast path for decoded json
+		if m, ok := v.(map[string]interface{}); ok && !step.isIndex {
+			field, ok := m[step.field]
+			if !ok {
+				return nil, errPathNotFound(name, path[:i+1])
+			}
+			v = field
+			continue
+		}
+		rv := reflect.ValueOf(v)
+		for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				break
+			}
+			rv = rv.Elem()
+		}
+		if !rv.IsValid() || (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+			return nil, zerror.BadRequest.Errorf(`%s is nil`, pathString(name, path[:i]))
+		}
+		next, err := step.get(rv)
+		if err != nil {
+			return nil, zerror.BadRequest.Errorf(`%s: %s`, pathString(name, path[:i+1]), err.Error())
+		}
+		if !next.IsValid() {
+			return nil, errPathNotFound(name, path[:i+1])
+		}
+		v = next.Interface()
+	}
+	return v, nil
+}
+
+func errPathNotFound(name string, path []pathStep) error {
+	return zerror.BadRequest.Errorf(`var: %s not found`, pathString(name, path))
+}
+
+// get returns the zero reflect.Value if the field doesn't exist
+func (s pathStep) get(rv reflect.Value) (reflect.Value, error) {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if !s.isIndex {
+			return reflect.Value{}, fmt.Errorf(`can not get field of %s`, rv.Type())
+		}
+		if s.index >= rv.Len() {
+			return reflect.Value{}, fmt.Errorf(`index out of range with length %d`, rv.Len())
+		}
+		return rv.Index(s.index), nil
+	case reflect.Map:
+		if s.isIndex || rv.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, fmt.Errorf(`can not index %s`, rv.Type())
+		}
+		return rv.MapIndex(reflect.ValueOf(s.field).Convert(rv.Type().Key())), nil
+	case reflect.Struct:
+		if s.isIndex {
+			return reflect.Value{}, fmt.Errorf(`can not index %s`, rv.Type())
+		}
+		index, ok := structFields(rv.Type())[s.field]
+		if !ok {
+			return reflect.Value{}, nil
+		}
+		return rv.Field(index), nil
+	}
+	return reflect.Value{}, fmt.Errorf(`can not get %s of %s`, s, rv.Type())
+}
+
+// field indexes of struct types by names
+var structFieldsCache sync.Map
+
+// structFields maps exported fields by their `expr` tags or names, fields tagged with `expr:"-"` are ignored
+func structFields(t reflect.Type) map[string]int {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.(map[string]int)
+	}
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != `` {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup(`expr`); ok {
+			if tag == `-` {
+				continue
+			}
+			name = tag
+		}
+		fields[name] = i
+	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,96 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAddress struct {
+	Country string `expr:"country"`
+	City    string
+	Secret  string `expr:"-"`
+	zip     string
+}
+
+type testItem struct {
+	SKU   string `expr:"sku"`
+	Price float32
+}
+
+type testOrder struct {
+	Items   []testItem   `expr:"items"`
+	Address *testAddress `expr:"address"`
+	Tags    map[string]int32
+}
+
+func TestSplitVar(t *testing.T) {
+	name, path, err := splitVar(`order.items[10].sku`)
+	require.Nil(t, err)
+	require.Equal(t, `order`, name)
+	require.Equal(t, []pathStep{{field: `items`}, {index: 10, isIndex: true}, {field: `sku`}}, path)
+	require.Equal(t, `$order.items[10].sku`, pathString(name, path))
+	name, path, err = splitVar(`a`)
+	require.Nil(t, err)
+	require.Equal(t, `a`, name)
+	require.Nil(t, path)
+	_, _, err = splitVar(`a[99999999999999999999]`)
+	require.NotNil(t, err)
+	_, err = Evaluate(`$a[99999999999999999999] = 1`, map[string]interface{}{`a`: []int{1}})
+	require.Contains(t, err.Error(), `line 1:0 invalid index: 99999999999999999999`)
+}
+
+func TestVariablePaths(t *testing.T) {
+	vars := map[string]interface{}{
+		`user`: map[string]interface{}{
+			`vip`:     true,
+			`address`: map[string]interface{}{`country`: `cn`},
+			`scores`:  []interface{}{1, 2.5},
+			`nothing`: nil,
+		},
+		`order`: &testOrder{
+			Items:   []testItem{{SKU: `a1`, Price: 1.5}, {SKU: `b2`, Price: 2}},
+			Address: &testAddress{Country: `us`, City: `ny`, Secret: `x`, zip: `1`},
+			Tags:    map[string]int32{`new`: 1},
+		},
+		`matrix`: [][]int{{1, 2}, {3, 4}},
+	}
+	trues := []string{
+		`$user.vip`,
+		`$user.address.country = 'cn'`,
+		`$user.scores[1] > $user.scores[0]`,
+		`$order.items[0].sku = 'a1'`,
+		`$order.items[1].Price * 2 = 4`,
+		`$order.address.country = 'us' and $order.address.City = 'ny'`,
+		`$order.Tags.new = 1`,
+		`$matrix[1][0] = 3`,
+		`startsWith($order.items[1].sku, 'b')`,
+	}
+	for _, input := range trues {
+		testEvaluator(t, input, vars, true)
+	}
+	invalids := map[string]string{
+		`$user.address.city = 'cn'`:      `var: $user.address.city not found`,
+		`$user.nothing.a = 1`:            `$user.nothing is nil`,
+		`$user.scores[2] = 1`:            `$user.scores[2]: index out of range with length 2`,
+		`$user.address[0] = 1`:           `$user.address[0]: can not index map[string]interface {}`,
+		`$order.items.sku = 'a1'`:        `$order.items.sku: can not get field of []expr.testItem`,
+		`$order.address.Secret = 'x'`:    `var: $order.address.Secret not found`,
+		`$order.address.zip = '1'`:       `var: $order.address.zip not found`,
+		`$order.address.country.a = 'x'`: `$order.address.country.a: can not get .a of string`,
+		`$order.address = 1`:             `variable name: order.address, type: *expr.testAddress can not be used as a value`,
+		`$missing.a = 1`:                 `var: missing not found`,
+	}
+	for input, msg := range invalids {
+		_, err := Evaluate(input, vars)
+		require.NotNil(t, err, input)
+		require.Contains(t, err.Error(), msg, input)
+	}
+}
+
+func TestVariablePathSyntax(t *testing.T) {
+	for _, input := range []string{`$a. b`, `$a.b.`, `$a[b]`, `$a[-1]`, `$a.1`} {
+		_, err := Parse(input)
+		require.NotNil(t, err, input)
+	}
+}
@@ -0,0 +1,556 @@
+package expr
+
+import (
+	"strconv"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// the parser follows Expr.g4 without ANTLR:
+// expressions are parsed by precedence climbing,
+// bool expressions are sequences of operands split by comparisons, which are grouped like ANTLR does, see parseComparisons
+
+// operand is a parsed expression, `$a`, `f()` and `($a)` can be used both as values and as bools
+type operand struct {
+	n node
+	// the extent of the operand including brackets around it
+	outer  span
+	isExpr bool
+	isBool bool
+	// a bracketed list of strings or numbers, which is only valid after in, like ('a', 'b')
+	list *listNode
+}
+
+// precedences of math operators, the higher binds tighter
+const (
+	precAddSub = iota + 1
+	precNeg
+	precMulDiv
+	precPow
+)
+
+var mathPrecedences = map[tokenKind]int{
+	tokenAdd: precAddSub, tokenSub: precAddSub,
+	tokenMul: precMulDiv, tokenDiv: precMulDiv, tokenMod: precMulDiv,
+	tokenPow: precPow,
+}
+
+type syntaxParser struct {
+	input  string
+	tokens []token
+	i      int
+	// syntax errors recovered from, see parseRecovered
+	errs ExprErrors
+}
+
+// parseNodes parses input into nodes, types are not checked.
+// all lexical errors are reported, or all syntax errors as parsing recovers from them
+func parseNodes(input string) (node, error) {
+	tokens, errs := lex(input)
+	if errs != nil {
+		return nil, errs
+	}
+	p := &syntaxParser{input: input, tokens: tokens}
+	return p.parse()
+}
+
+func (p *syntaxParser) parse() (node, error) {
+	x := p.parseOr()
+	if tok := p.peek(); tok.kind != tokenEOF {
+		p.fail(p.mismatched(tok))
+	} else if _, err := p.value(x); err != nil {
+		p.fail(err)
+	}
+	if p.errs != nil {
+		return nil, p.errs
+	}
+	return x.n, nil
+}
+
+// fail records err, errors at the position of a recorded one are left out as they're caused by it
+func (p *syntaxParser) fail(err error) {
+	e := err.(*ExprError)
+	for _, recorded := range p.errs {
+		if recorded.Offset == e.Offset {
+			return
+		}
+	}
+	p.errs = append(p.errs, e)
+}
+
+// parseRecovered parses comparisons, a syntax error in them is recorded and their tokens are skipped,
+// the skipped tokens are an operand which can be used anywhere, so they cause no more errors
+func (p *syntaxParser) parseRecovered() operand {
+	start := p.i
+	x, err := p.parseComparisons()
+	if err == nil {
+		return x
+	}
+	p.fail(err)
+	p.i = start
+	p.skip()
+	sp := span{start: p.tokens[start].start, end: p.tokens[p.i].start}
+	return operand{n: &literalNode{span: sp, val: boolValue(false)}, outer: sp, isExpr: true, isBool: true}
+}
+
+// skip skips tokens until and, or, a comma or a closing bracket out of the brackets opened after them, or the end
+func (p *syntaxParser) skip() {
+	depth := 0
+	for {
+		switch p.peek().kind {
+		case tokenEOF:
+			return
+		case tokenAnd, tokenOr, tokenComma:
+			if depth == 0 {
+				return
+			}
+		case tokenLParen, tokenLBracket, tokenLBrace:
+			depth++
+		case tokenRParen, tokenRBracket, tokenRBrace:
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+		p.i++
+	}
+}
+
+func (p *syntaxParser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *syntaxParser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokenEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *syntaxParser) expect(kind tokenKind, text string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, newExprError(p.input, tok.span, CategorySyntax, zerror.BadRequest.Errorf(`mismatched input '%s' expecting '%s'`, tok.text, text))
+	}
+	return tok, nil
+}
+
+func (p *syntaxParser) mismatched(tok token) error {
+	return newExprError(p.input, tok.span, CategorySyntax, zerror.BadRequest.Errorf(`mismatched input '%s'`, tok.text))
+}
+
+// errorAt reports the token starting at offset, it's used for operands which can't be used where they are
+func (p *syntaxParser) errorAt(offset int) error {
+	for _, tok := range p.tokens {
+		if tok.start >= offset {
+			return p.mismatched(tok)
+		}
+	}
+	return p.mismatched(p.tokens[len(p.tokens)-1])
+}
+
+// value is the node of x where any expression is expected, like args and elements
+func (p *syntaxParser) value(x operand) (node, error) {
+	if !x.isExpr && !x.isBool {
+		return nil, p.errorAt(x.outer.start)
+	}
+	return x.n, nil
+}
+
+// parseOr parses a whole expression, syntax errors are recorded, see parseRecovered
+func (p *syntaxParser) parseOr() operand {
+	return p.parseLogic(tokenOr, operatorOr, p.parseAnd)
+}
+
+func (p *syntaxParser) parseAnd() operand {
+	return p.parseLogic(tokenAnd, operatorAnd, p.parseRecovered)
+}
+
+func (p *syntaxParser) parseLogic(kind tokenKind, op operator, parseOperand func() operand) operand {
+	x := parseOperand()
+	for p.peek().kind == kind {
+		tok := p.next()
+		if !x.isBool {
+			p.fail(p.mismatched(tok))
+		}
+		y := parseOperand()
+		if !y.isBool {
+			p.fail(p.errorAt(y.outer.start))
+		}
+		sp := span{start: x.outer.start, end: y.outer.end}
+		x = operand{n: &binaryNode{span: sp, op: op, x: x.n, y: y.n}, outer: sp, isBool: true}
+	}
+	return x
+}
+
+// comparison is an operand of parseComparisons with the offsets of ! before it
+type comparison struct {
+	nots []int
+	operand
+}
+
+func isComparison(kind tokenKind) bool {
+	return kind >= tokenGT && kind <= tokenNotIn
+}
+
+// units of comparisons
+const (
+	unitNone = iota
+	// two operands compared, or tested by in
+	unitPair
+	// a bool operand
+	unitSingle
+)
+
+// parseComparisons parses operands split by comparisons and in, like `$a = $b = 1`.
+// the operands are grouped into units which are joined by = and != comparing bools,
+// a unit is a comparison of two values, like `$a > 1`, or a bool operand, like `$b`.
+// like ANTLR, the first unit is a comparison of values if the rest can still be grouped,
+// so `$a = $b = $c` is `($a = $b) = $c`, but `$a = $b = 1` is `$a = ($b = 1)`
+func (p *syntaxParser) parseComparisons() (operand, error) {
+	first, err := p.parseComparison()
+	if err != nil {
+		return first.operand, err
+	}
+	if !isComparison(p.peek().kind) && len(first.nots) == 0 {
+		return first.operand, nil
+	}
+	items := []comparison{first}
+	var ops []token
+	for isComparison(p.peek().kind) {
+		ops = append(ops, p.next())
+		item, err := p.parseComparison()
+		if err != nil {
+			return item.operand, err
+		}
+		items = append(items, item)
+	}
+
+	// units[i] is the unit starting at items[i] which leaves the rest groupable
+	units := make([]int, len(items))
+	groupable := func(end int) bool {
+		return end == len(items)-1 || (ops[end].kind == tokenEQ || ops[end].kind == tokenNEQ) && units[end+1] != unitNone
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		if p.isPair(items, ops, i) && groupable(i+1) {
+			units[i] = unitPair
+		} else if items[i].isBool && groupable(i) {
+			units[i] = unitSingle
+		}
+	}
+	if units[0] == unitNone {
+		return first.operand, p.groupError(items, ops)
+	}
+
+	var x operand
+	for i := 0; i < len(items); i++ {
+		unit := p.unit(items, ops, i, units[i])
+		if i > 0 {
+			sp := span{start: x.outer.start, end: unit.outer.end}
+			n := &binaryNode{span: sp, op: binaryOperators[ops[i-1].kind], x: x.n, y: unit.n, bools: true}
+			unit = operand{n: n, outer: sp, isBool: true}
+		}
+		x = unit
+		if units[i] == unitPair {
+			i++
+		}
+	}
+	return x, nil
+}
+
+// isPair reports if items[i] and items[i+1] can be compared, or tested by in
+func (p *syntaxParser) isPair(items []comparison, ops []token, i int) bool {
+	if i+1 >= len(items) || !items[i].isExpr || len(items[i+1].nots) > 0 {
+		return false
+	}
+	if ops[i].kind == tokenIn || ops[i].kind == tokenNotIn {
+		return items[i+1].isExpr || items[i+1].list != nil
+	}
+	return items[i+1].isExpr
+}
+
+// groupError finds the first operand or comparison which can't be grouped, preferring units like the grouping
+func (p *syntaxParser) groupError(items []comparison, ops []token) error {
+	for i := 0; i < len(items); i++ {
+		switch {
+		case p.isPair(items, ops, i):
+			i++
+		case !items[i].isBool:
+			return p.errorAt(items[i].outer.start)
+		}
+		if i < len(ops) && ops[i].kind != tokenEQ && ops[i].kind != tokenNEQ {
+			return p.mismatched(ops[i])
+		}
+	}
+	return p.mismatched(ops[0])
+}
+
+// unit builds the unit starting at items[i], ! before it applies to the whole unit
+func (p *syntaxParser) unit(items []comparison, ops []token, i, unit int) operand {
+	x := items[i].operand
+	if unit == unitPair {
+		y := items[i+1]
+		sp := span{start: x.outer.start, end: y.outer.end}
+		switch ops[i].kind {
+		case tokenIn, tokenNotIn:
+			in := &inNode{span: sp, not: ops[i].kind == tokenNotIn, x: x.n, y: y.n}
+			if y.list != nil {
+				in.y, in.literals = y.list, true
+			}
+			x = operand{n: in, outer: sp}
+		default:
+			x = operand{n: &binaryNode{span: sp, op: binaryOperators[ops[i].kind], x: x.n, y: y.n}, outer: sp}
+		}
+	}
+	nots := items[i].nots
+	for j := len(nots) - 1; j >= 0; j-- {
+		sp := span{start: nots[j], end: x.outer.end}
+		x = operand{n: &unaryNode{span: sp, op: operatorNot, x: x.n}, outer: sp}
+	}
+	x.isExpr, x.isBool, x.list = false, true, nil
+	return x
+}
+
+func (p *syntaxParser) parseComparison() (comparison, error) {
+	var c comparison
+	for p.peek().kind == tokenNot {
+		c.nots = append(c.nots, p.next().start)
+	}
+	if tok := p.peek(); tok.kind == tokenBool {
+		p.next()
+		c.operand = operand{n: &literalNode{span: tok.span, val: boolValue(tok.text == `true`)}, outer: tok.span, isBool: true}
+		return c, nil
+	}
+	var err error
+	c.operand, err = p.parseMath(0)
+	return c, err
+}
+
+// parseMath parses math operators binding at least as tight as prec
+func (p *syntaxParser) parseMath(prec int) (operand, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return x, err
+	}
+	for {
+		tok := p.peek()
+		q, ok := mathPrecedences[tok.kind]
+		if !ok || q < prec {
+			return x, nil
+		}
+		if !x.isExpr {
+			return x, p.mismatched(tok)
+		}
+		p.next()
+		next := q + 1
+		// ** is right associative
+		if tok.kind == tokenPow {
+			next = q
+		}
+		y, err := p.parseMath(next)
+		if err != nil {
+			return y, err
+		}
+		if !y.isExpr {
+			return y, p.errorAt(y.outer.start)
+		}
+		sp := span{start: x.outer.start, end: y.outer.end}
+		x = operand{n: &binaryNode{span: sp, op: binaryOperators[tok.kind], x: x.n, y: y.n}, outer: sp, isExpr: true}
+	}
+}
+
+func (p *syntaxParser) parsePrimary() (operand, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenSub:
+		// - binds looser than * and **, -2 * 3 is -(2 * 3)
+		x, err := p.parseMath(precNeg)
+		if err != nil {
+			return x, err
+		}
+		if !x.isExpr {
+			return x, p.errorAt(x.outer.start)
+		}
+		sp := span{start: tok.start, end: x.outer.end}
+		return operand{n: &unaryNode{span: sp, op: operatorNeg, x: x.n}, outer: sp, isExpr: true}, nil
+	case tokenInt, tokenFloat:
+		lit, err := p.number(tok)
+		return operand{n: lit, outer: tok.span, isExpr: true}, err
+	case tokenString:
+		lit := &literalNode{span: tok.span, val: stringValue(convertText(tok.text))}
+		return operand{n: lit, outer: tok.span, isExpr: true}, nil
+	case tokenVar:
+		v, err := newVariableNode(tok.span, tok.text)
+		if err != nil {
+			// indexes are digits, they only fail out of range like numbers
+			return operand{}, newExprError(p.input, tok.span, CategoryLexical, err)
+		}
+		return operand{n: v, outer: tok.span, isExpr: true, isBool: true}, nil
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+		ident := &identifierNode{span: tok.span, name: tok.text}
+		return operand{n: ident, outer: tok.span, isExpr: true, isBool: true}, nil
+	case tokenLParen:
+		return p.parseBracket(tok)
+	case tokenLBracket:
+		return p.parseList(tok)
+	case tokenLBrace:
+		return p.parseMap(tok)
+	}
+	return operand{outer: tok.span}, p.mismatched(tok)
+}
+
+func (p *syntaxParser) number(tok token) (*literalNode, error) {
+	if tok.kind == tokenFloat {
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.invalidNumber(tok)
+		}
+		return &literalNode{span: tok.span, val: floatValue(f)}, nil
+	}
+	i, err := strconv.ParseInt(tok.text, 10, 64)
+	if err != nil {
+		return nil, p.invalidNumber(tok)
+	}
+	return &literalNode{span: tok.span, val: intValue(i)}, nil
+}
+
+func (p *syntaxParser) invalidNumber(tok token) error {
+	return newExprError(p.input, tok.span, CategoryLexical, zerror.BadRequest.Errorf(`invalid number: %s`, tok.text))
+}
+
+func (p *syntaxParser) parseCall(name token) (operand, error) {
+	p.next()
+	call := &callNode{name: name.text}
+	args, end, err := p.parseValues(tokenRParen, `)`)
+	if err != nil {
+		return operand{}, err
+	}
+	call.args = args
+	call.span = span{start: name.start, end: end.end}
+	return operand{n: call, outer: call.span, isExpr: true, isBool: true}, nil
+}
+
+func (p *syntaxParser) parseList(open token) (operand, error) {
+	elems, end, err := p.parseValues(tokenRBracket, `]`)
+	if err != nil {
+		return operand{}, err
+	}
+	list := &listNode{span: span{start: open.start, end: end.end}, elems: elems}
+	return operand{n: list, outer: list.span, isExpr: true}, nil
+}
+
+// parseValues parses values split by commas until the closing token
+func (p *syntaxParser) parseValues(closing tokenKind, text string) ([]node, token, error) {
+	var values []node
+	if tok := p.peek(); tok.kind == closing {
+		return values, p.next(), nil
+	}
+	for {
+		v, err := p.value(p.parseOr())
+		if err != nil {
+			return nil, token{}, err
+		}
+		values = append(values, v)
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.next()
+	}
+	end, err := p.expect(closing, text)
+	return values, end, err
+}
+
+func (p *syntaxParser) parseMap(open token) (operand, error) {
+	m := &mapNode{}
+	if p.peek().kind != tokenRBrace {
+		for {
+			key, err := p.expect(tokenString, `string`)
+			if err != nil {
+				return operand{}, err
+			}
+			if _, err := p.expect(tokenColon, `:`); err != nil {
+				return operand{}, err
+			}
+			v, err := p.value(p.parseOr())
+			if err != nil {
+				return operand{}, err
+			}
+			m.keys = append(m.keys, convertText(key.text))
+			m.values = append(m.values, v)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	end, err := p.expect(tokenRBrace, `}`)
+	if err != nil {
+		return operand{}, err
+	}
+	m.span = span{start: open.start, end: end.end}
+	return operand{n: m, outer: m.span, isExpr: true}, nil
+}
+
+// parseBracket parses an expression or a bool expression in brackets,
+// or a list of strings or numbers, like ('a', 'b') or (1, 2.5)
+func (p *syntaxParser) parseBracket(open token) (operand, error) {
+	if list, ok := p.parseLiterals(open); ok {
+		x := operand{n: list, outer: list.span, list: list}
+		// (1) is also an expression
+		if len(list.elems) == 1 {
+			x.n, x.isExpr = list.elems[0], true
+		}
+		return x, nil
+	}
+	x := p.parseOr()
+	end, err := p.expect(tokenRParen, `)`)
+	if err != nil {
+		return x, err
+	}
+	x.outer = span{start: open.start, end: end.end}
+	x.list = nil
+	return x, nil
+}
+
+// parseLiterals parses a list of strings or numbers after open, the tokens are not consumed if it isn't one
+func (p *syntaxParser) parseLiterals(open token) (*listNode, bool) {
+	list := &listNode{}
+	isString := p.peek().kind == tokenString
+	for i := p.i; ; i += 2 {
+		tok := p.tokens[i]
+		switch {
+		case isString && tok.kind == tokenString:
+			list.elems = append(list.elems, &literalNode{span: tok.span, val: stringValue(convertText(tok.text))})
+		case !isString && (tok.kind == tokenInt || tok.kind == tokenFloat):
+			lit, err := p.number(tok)
+			if err != nil {
+				return nil, false
+			}
+			list.elems = append(list.elems, lit)
+		default:
+			return nil, false
+		}
+		switch p.tokens[i+1].kind {
+		case tokenComma:
+		case tokenRParen:
+			list.span = span{start: open.start, end: p.tokens[i+1].end}
+			p.i = i + 2
+			return list, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+// newVariableNode creates a node from the text of a VAR token
+func newVariableNode(sp span, text string) (*variableNode, error) {
+	name, path, err := splitVar(text[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &variableNode{span: sp, name: name, path: path}, nil
+}
@@ -0,0 +1,84 @@
+package expr
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// dumpSyntax prints n with spans and flags, which formatNode leaves out
+func dumpSyntax(n node) string {
+	dump := func(nodes []node) string {
+		s := make([]string, len(nodes))
+		for i, n := range nodes {
+			s[i] = dumpSyntax(n)
+		}
+		return strings.Join(s, `,`)
+	}
+	switch n := n.(type) {
+	case *literalNode:
+		return fmt.Sprintf(`lit%v[%s]`, n.span, n.val.literal())
+	case *variableNode:
+		return fmt.Sprintf(`var%v[%s %v]`, n.span, n.name, n.path)
+	case *identifierNode:
+		return fmt.Sprintf(`id%v[%s]`, n.span, n.name)
+	case *unaryNode:
+		return fmt.Sprintf(`un%v[%s %s]`, n.span, n.op, dumpSyntax(n.x))
+	case *binaryNode:
+		return fmt.Sprintf(`bin%v[%s %v %s %s]`, n.span, n.op, n.bools, dumpSyntax(n.x), dumpSyntax(n.y))
+	case *inNode:
+		return fmt.Sprintf(`in%v[%v %v %s %s]`, n.span, n.not, n.literals, dumpSyntax(n.x), dumpSyntax(n.y))
+	case *listNode:
+		return fmt.Sprintf(`list%v[%s]`, n.span, dump(n.elems))
+	case *mapNode:
+		return fmt.Sprintf(`map%v[%v %s]`, n.span, n.keys, dump(n.values))
+	case *callNode:
+		return fmt.Sprintf(`call%v[%s %s]`, n.span, n.name, dump(n.args))
+	}
+	return fmt.Sprintf(`%T`, n)
+}
+
+var syntaxAtoms = []string{`$a`, `$b.c[0]`, `a`, `f()`, `1`, `-1`, `2.5`, `'s'`, `'x\'y'`, `true`, `(1)`, `('a')`, `(1, 2)`, `[1, $a]`, `{'k': 1}`}
+var syntaxOperators = []string{`=`, `!=`, `>`, `<=`, `and`, `or`, `+`, `-`, `*`, `/`, `%`, `**`, `in`, `not in`}
+
+func randomSyntax(r *rand.Rand, depth int) string {
+	if depth <= 0 || r.Intn(3) == 0 {
+		return syntaxAtoms[r.Intn(len(syntaxAtoms))]
+	}
+	switch r.Intn(6) {
+	case 0:
+		return `(` + randomSyntax(r, depth-1) + `)`
+	case 1:
+		return `!` + randomSyntax(r, depth-1)
+	case 2:
+		return `f(` + randomSyntax(r, depth-1) + `, ` + randomSyntax(r, depth-1) + `)`
+	case 3:
+		return `-` + randomSyntax(r, depth-1)
+	}
+	return randomSyntax(r, depth-1) + ` ` + syntaxOperators[r.Intn(len(syntaxOperators))] + ` ` + randomSyntax(r, depth-1)
+}
+
+// the hand-written parser builds the same trees as the ANTLR grammar, errors included
+func TestParseNodes(t *testing.T) {
+	inputs := []string{
+		`$a = $b = $c`, `$a = $b = 1`, `!$a = 1`, `$a and $b = $c or !$d`, `-2*3`, `2**3**2`, `1-1`, `1 - -1`,
+		`'it\'s'`, `'a\\' = 'b'`, `'a\'`, `$a not in (1, 2)`, `$a not  in (1)`, `$a in ('a', 'b')`, `$a in [1, 'b']`,
+		`$a in {'k': 1}`, `$a.b[0].c[`, `f(g($a), [1, {'k': [2]}])`, `(true)`, `((1))`, `true = !false`, `$`, `#`,
+		`1 in (1, 'a')`, `$a > 1 = true`, ``, `(1, 2)`, `$a[1][2] <= 2.5e`, `99999999999999999999 > 1`,
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		inputs = append(inputs, randomSyntax(r, 4))
+	}
+	for _, input := range inputs {
+		want, wantErr := parseANTLR(input)
+		got, err := parseSyntax(input)
+		require.Equal(t, wantErr == nil, err == nil, `%s: %v, %v`, input, wantErr, err)
+		if err == nil {
+			require.Equal(t, dumpSyntax(want.root), dumpSyntax(got.root), input)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package expr
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// Program is a compiled expression.
+// it's immutable after Compile and safe to be evaluated from many goroutines
+type Program struct {
+	expr string
+	tree *Tree
+	root node
+	code *bytecode
+	// static kind of the result
+	kind kind
+	// the result is a number, even if kind is kindAny
+	number bool
+	// default options from the Env
+	evalOpts []EvalOption
+	// known variables bound by PartialEval which can't be literals
+	bound map[string]interface{}
+	// functions resolved when compiled by names, including those folded out of code
+	resolved map[string]*function
+}
+
+// Compile parses and checks expr against the default Env, the result can be evaluated many times
+func Compile(expr string) (*Program, error) {
+	return defaultEnv.Compile(expr)
+}
+
+// CompileValue is like Compile, but expr can result in any type, such as `$price * 0.9 - $discount`
+func CompileValue(expr string) (*Program, error) {
+	return defaultEnv.CompileValue(expr)
+}
+
+// Compile is like the package level Compile, but functions registered on l are visible to the program
+func (l *listenerForParse) Compile(expr string) (*Program, error) {
+	tree, err := l.ParseWithCache(expr)
+	if err != nil {
+		return nil, err
+	}
+	return l.compileTree(expr, tree)
+}
+
+// CompileValue is like the package level CompileValue, but functions registered on l are visible to the program
+func (l *listenerForParse) CompileValue(expr string) (*Program, error) {
+	tree, err := l.ParseValueWithCache(expr)
+	if err != nil {
+		return nil, err
+	}
+	return l.compileTree(expr, tree)
+}
+
+func (l *listenerForParse) compileTree(expr string, tree *Tree) (*Program, error) {
+	resolved := map[string]*function{}
+	getFunc := func(name string) (*function, error) {
+		fn, err := l.getFunc(name)
+		if err == nil {
+			resolved[name] = fn
+		}
+		return fn, err
+	}
+	code, root, k, err := compileTree(tree, getFunc)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{
+		expr: expr, tree: tree, root: root, code: code, kind: k, number: isNumber(root), evalOpts: l.env.evalOpts,
+		resolved: resolved,
+	}, nil
+}
+
+// expectBool fails if the program statically results in a type other than bool
+func (p *Program) expectBool() error {
+	if p.number && p.kind == kindAny {
+		return errorOfTree(p.tree, CategoryType, zerror.BadRequest.Errorf(`expect bool expression, got number`))
+	}
+	if p.kind != kindAny && p.kind != kindBool {
+		return errorOfTree(p.tree, CategoryType, zerror.BadRequest.Errorf(`expect bool expression, got %s`, p.kind))
+	}
+	return nil
+}
+
+// String returns the source expression
+func (p *Program) String() string {
+	return p.expr
+}
+
+// Disassemble returns the optimized expression and the bytecode which runs when the program is evaluated
+func (p *Program) Disassemble() string {
+	return `; ` + formatNode(p.root) + "\n" + p.code.String()
+}
+
+// Constant returns the result of a program which doesn't depend on variables, like `1 + 2 > 2`,
+// it's how PartialEval reports a definite result
+func (p *Program) Constant() (interface{}, bool) {
+	if lit, ok := p.root.(*literalNode); ok {
+		return lit.val.interfaceValue(), true
+	}
+	return nil, false
+}
+
+// Variables returns names of variables which should be passed to evaluations
+func (p *Program) Variables() []string {
+	var names []string
+	seen := map[string]bool{}
+	inspect(p.root, func(n node) bool {
+		if v, ok := n.(*variableNode); ok && !seen[v.name] {
+			if _, bound := p.bound[v.name]; !bound {
+				names = append(names, v.name)
+			}
+			seen[v.name] = true
+		}
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// ResultType returns the statically inferred result type, nil if it's only known at runtime
+func (p *Program) ResultType() reflect.Type {
+	return p.kind.reflectType()
+}
+
+// Eval evaluates the program with custom variables
+func (p *Program) Eval(vars map[string]interface{}) (bool, error) {
+	return p.EvalContext(context.Background(), vars)
+}
+
+// EvalContext is like Eval, but stops once ctx is done and passes ctx to functions which accept it, a nil ctx never stops
+func (p *Program) EvalContext(ctx context.Context, vars map[string]interface{}, opts ...EvalOption) (bool, error) {
+	result, err := p.run(ctx, vars, opts)
+	if err != nil {
+		return false, err
+	}
+	if result.kind != kindBool {
+		return false, zerror.BadRequest.Errorf(`expect bool result, got %s: %s`, result.kind, result)
+	}
+	return result.b, nil
+}
+
+// EvalValue evaluates the program to bool, int64, float64, string, []interface{} of lists or map[string]interface{} of maps
+func (p *Program) EvalValue(vars map[string]interface{}) (interface{}, error) {
+	return p.EvalValueContext(context.Background(), vars)
+}
+
+// EvalValueContext is like EvalValue, see EvalContext
+func (p *Program) EvalValueContext(ctx context.Context, vars map[string]interface{}, opts ...EvalOption) (interface{}, error) {
+	result, err := p.run(ctx, vars, opts)
+	if err != nil {
+		return nil, err
+	}
+	return result.interfaceValue(), nil
+}
+
+// EvalFloat evaluates the program to a number, integers are converted to float64
+func (p *Program) EvalFloat(vars map[string]interface{}) (float64, error) {
+	result, err := p.run(context.Background(), vars, nil)
+	if err != nil {
+		return 0, err
+	}
+	if !result.kind.isNumber() {
+		return 0, zerror.BadRequest.Errorf(`expect number result, got %s: %s`, result.kind, result)
+	}
+	return result.float(), nil
+}
+
+// EvalString evaluates the program to a string
+func (p *Program) EvalString(vars map[string]interface{}) (string, error) {
+	result, err := p.run(context.Background(), vars, nil)
+	if err != nil {
+		return ``, err
+	}
+	if result.kind != kindString {
+		return ``, zerror.BadRequest.Errorf(`expect string result, got %s: %s`, result.kind, result)
+	}
+	return result.s, nil
+}
+
+func (p *Program) run(ctx context.Context, vars map[string]interface{}, opts []EvalOption) (value, error) {
+	m := p.newVM(ctx, vars, opts)
+	defer m.release()
+	return m.run(p.code)
+}
+
+// newVM returns a vm from the pool for an evaluation of p, it should be released after running
+func (p *Program) newVM(ctx context.Context, vars map[string]interface{}, opts []EvalOption) *vm {
+	return newVM(ctx, vars, p.bound, p.evalOpts, opts)
+}
+
+// newVM returns a vm from the pool, options of later sets take precedence, a nil ctx is context.Background()
+func newVM(ctx context.Context, vars, bound map[string]interface{}, optSets ...[]EvalOption) *vm {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	m := vmPool.Get().(*vm)
+	m.vars = vars
+	m.bound = bound
+	m.ctx = ctx
+	for _, opts := range optSets {
+		for _, opt := range opts {
+			opt(&m.evalOptions)
+		}
+	}
+	return m
+}
+
+// per evaluation scratch state, reused between evaluations
+var vmPool = sync.Pool{
+	New: func() interface{} {
+		return &vm{}
+	},
+}
+
+func (m *vm) release() {
+	m.reset()
+	vmPool.Put(m)
+}
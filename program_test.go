@@ -0,0 +1,230 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgramConcurrentEval(t *testing.T) {
+	program, err := Compile(`$int * 2 = $expect and startsWith($string, 's')`)
+	require.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				result, err := program.Eval(map[string]interface{}{
+					`int`:    i,
+					`expect`: i * 2,
+					`string`: `str`,
+				})
+				require.Nil(t, err)
+				require.True(t, result)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestProgramEvalError(t *testing.T) {
+	program, err := Compile(`$a > 1`)
+	require.Nil(t, err)
+	_, err = program.Eval(nil)
+	require.NotNil(t, err)
+	// a failed evaluation must not leak into the next one
+	result, err := program.Eval(map[string]interface{}{`a`: 2})
+	require.Nil(t, err)
+	require.True(t, result)
+
+	_, err = program.Eval(map[string]interface{}{`a`: struct{}{}})
+	require.NotNil(t, err)
+}
+
+func TestProgramWithParserFuncs(t *testing.T) {
+	parser := NewParser()
+	require.Nil(t, parser.RegisterFunc(`local`, func() bool { return true }))
+	program, err := parser.Compile(`local()`)
+	require.Nil(t, err)
+	result, err := program.Eval(nil)
+	require.Nil(t, err)
+	require.True(t, result)
+}
+
+func TestProgramDoesNotModifyVars(t *testing.T) {
+	program, err := Compile(`$int = 1`)
+	require.Nil(t, err)
+	vars := map[string]interface{}{`int`: 1}
+	_, err = program.Eval(vars)
+	require.Nil(t, err)
+	require.Equal(t, 1, vars[`int`])
+}
+
+func TestEvalContextCanceled(t *testing.T) {
+	parser := NewParser()
+	ctx, cancel := context.WithCancel(context.Background())
+	called := 0
+	require.Nil(t, parser.RegisterFunc(`cancel`, func() bool {
+		called++
+		cancel()
+		return true
+	}))
+	program, err := parser.Compile(`cancel() and cancel()`)
+	require.Nil(t, err)
+	_, err = program.EvalContext(ctx, nil)
+	require.True(t, Canceled.Cause(err), err)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, 1, called)
+
+	_, err = EvaluateContext(ctx, `true`, nil)
+	require.True(t, Canceled.Cause(err), err)
+}
+
+func TestEvalNilContext(t *testing.T) {
+	program, err := Compile(`$a > 1`)
+	require.Nil(t, err)
+	got, err := program.EvalContext(nil, map[string]interface{}{`a`: 2})
+	require.Nil(t, err)
+	require.True(t, got)
+
+	rs, err := NewRuleSet(Rule{Name: `a`, Expr: `$a > 1`})
+	require.Nil(t, err)
+	result, err := rs.EvalContext(nil, map[string]interface{}{`a`: 2}, AllMatching)
+	require.Nil(t, err)
+	require.Len(t, result.Matched, 1)
+}
+
+func TestContextFunction(t *testing.T) {
+	parser := NewParser()
+	slow := func(ctx context.Context, d int64) (bool, error) {
+		select {
+		case <-time.After(time.Duration(d) * time.Millisecond):
+			return true, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	require.Nil(t, parser.RegisterFunc(`slow`, slow))
+	program, err := parser.Compile(`slow(1000)`)
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = program.EvalContext(ctx, nil)
+	require.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+	// context is not counted as an argument
+	_, err = parser.Compile(`slow()`)
+	require.NotNil(t, err)
+	program, err = parser.Compile(`slow(1)`)
+	require.Nil(t, err)
+	result, err := program.Eval(nil)
+	require.Nil(t, err)
+	require.True(t, result)
+}
+
+func TestCostBudget(t *testing.T) {
+	parser := NewParser()
+	require.Nil(t, parser.RegisterFunc(`expensive`, func() bool { return true }, WithCost(100)))
+	cases := []struct {
+		expr     string
+		budget   int64
+		exceeded bool
+	}{
+		{`$a + 2 > 2`, 0, false},
+		{`$a + 2 > 2`, 5, false},
+		{`$a + 2 > 2`, 4, true},
+		// constants are folded when compiled
+		{`1 + 2 > 2`, 1, false},
+		{`expensive()`, 100, true},
+		{`expensive()`, 101, false},
+		{`false and expensive()`, 2, false},
+		{`t_bool() and t_bool()`, 2, false},
+	}
+	for _, c := range cases {
+		program, err := parser.Compile(c.expr)
+		require.Nil(t, err, c.expr)
+		_, err = program.EvalContext(context.Background(), map[string]interface{}{`a`: 1}, WithCostBudget(c.budget))
+		require.Equal(t, c.exceeded, BudgetExceeded.Cause(err), c.expr, err)
+		if !c.exceeded {
+			require.Nil(t, err, c.expr)
+		}
+	}
+}
+
+func TestEvalValue(t *testing.T) {
+	vars := map[string]interface{}{`price`: 100, `discount`: 5.5, `name`: `bob`, `vip`: true}
+	cases := map[string]interface{}{
+		`$price * 0.9 - $discount`: 84.5,
+		`$price - 1`:               int64(99),
+		`$name`:                    `bob`,
+		`t_string()`:               `test`,
+		`$vip`:                     true,
+		`$price > 1 and $vip`:      true,
+		`($price)`:                 int64(100),
+	}
+	for input, expect := range cases {
+		result, err := EvaluateValue(input, vars)
+		require.Nil(t, err, input)
+		require.Equal(t, expect, result, input)
+	}
+
+	program, err := CompileValue(`$price * 2`)
+	require.Nil(t, err)
+	f, err := program.EvalFloat(vars)
+	require.Nil(t, err)
+	require.Equal(t, float64(200), f)
+	_, err = program.EvalString(vars)
+	require.NotNil(t, err)
+	_, err = program.Eval(vars)
+	require.NotNil(t, err)
+
+	program, err = CompileValue(`$name`)
+	require.Nil(t, err)
+	s, err := program.EvalString(vars)
+	require.Nil(t, err)
+	require.Equal(t, `bob`, s)
+	_, err = program.Eval(vars)
+	require.NotNil(t, err)
+
+	// bool entry points reject expressions statically known not to be bool
+	for _, input := range []string{`$price * 2`, `t_int()`, `'a'`} {
+		_, err := Compile(input)
+		require.NotNil(t, err, input)
+		_, err = CompileValue(input)
+		require.Nil(t, err, input)
+	}
+}
+
+func ExampleEvaluateValue() {
+	result, err := EvaluateValue(`$price * 0.9 - $discount`, map[string]interface{}{`price`: 100, `discount`: 5})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(result)
+	// Output:
+	// 85
+}
+
+func ExampleCompile() {
+	program, err := Compile(`$car in ('bwm', 'byd') and startsWith($car, 'b')`)
+	if err != nil {
+		panic(err)
+	}
+	for _, car := range []string{`byd`, `audi`} {
+		result, err := program.Eval(map[string]interface{}{`car`: car})
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(result)
+	}
+	// Output:
+	// true
+	// false
+}
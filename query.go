@@ -0,0 +1,201 @@
+package expr
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// translators of expressions into queries of databases: SQL, MongoDB and Elasticsearch
+
+type queryOptions struct {
+	fields map[string]string
+}
+
+// QueryOption configures ToMongo and ToElastic
+type QueryOption func(*queryOptions)
+
+// WithFields maps variables to fields of documents, keys are variables without `$` like `user.age`.
+// with fields, variables out of them can't be translated,
+// without fields, variables are fields of the same paths, like `user.tags.0` for `$user.tags[0]`
+func WithFields(fields map[string]string) QueryOption {
+	return func(o *queryOptions) {
+		o.fields = fields
+	}
+}
+
+func newQueryOptions(opts []QueryOption) queryOptions {
+	var o queryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// field returns the field of n, indexes are allowed only if the target has them
+func (o *queryOptions) field(target string, n *variableNode, indexes bool) (string, error) {
+	if o.fields != nil {
+		field, ok := o.fields[pathString(n.name, n.path)[1:]]
+		if !ok {
+			return ``, errTranslate(target, n, `the variable isn't mapped to a field`)
+		}
+		return field, nil
+	}
+	var b strings.Builder
+	b.WriteString(n.name)
+	for _, step := range n.path {
+		b.WriteString(`.`)
+		if !step.isIndex {
+			b.WriteString(step.field)
+			continue
+		}
+		if !indexes {
+			return ``, errTranslate(target, n, `variables with indexes must be mapped to fields`)
+		}
+		b.WriteString(strconv.Itoa(step.index))
+	}
+	return b.String(), nil
+}
+
+func errTranslate(target string, n node, reason string) error {
+	return zerror.BadRequest.Errorf(`%s: can not translate %s, %s`, target, formatNode(n), reason)
+}
+
+// inElems returns the elements of the list, or the keys of the map, which x of n is tested in
+func inElems(target string, n *inNode) ([]node, error) {
+	switch y := n.y.(type) {
+	case *listNode:
+		return y.elems, nil
+	case *mapNode:
+		elems := make([]node, len(y.keys))
+		for i, key := range y.keys {
+			elems[i] = &literalNode{span: y.values[i].pos(), val: stringValue(key)}
+		}
+		return elems, nil
+	case *literalNode:
+		// known lists and maps of PartialEval
+		if y.val.kind == kindList || y.val.kind == kindMap {
+			return literalElems(target, y)
+		}
+	}
+	return nil, errTranslate(target, n, `in is only supported with lists and maps`)
+}
+
+// literalElems returns the elements of the list, or the sorted keys of the map, of lit like inElems
+func literalElems(target string, lit *literalNode) ([]node, error) {
+	rv := reflect.ValueOf(lit.val.x)
+	if lit.val.kind == kindMap {
+		keys := make([]string, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		elems := make([]node, len(keys))
+		for i, key := range keys {
+			elems[i] = &literalNode{span: lit.span, val: stringValue(key)}
+		}
+		return elems, nil
+	}
+	elems := make([]node, rv.Len())
+	for i := range elems {
+		v, err := valueOf(`element`, rv.Index(i).Interface())
+		if err != nil {
+			return nil, errTranslate(target, lit, `elements of in must be bools, numbers or strings`)
+		}
+		elems[i] = &literalNode{span: lit.span, val: v}
+	}
+	return elems, nil
+}
+
+// inValues is inElems for targets which take values, elements must be constants
+func inValues(target string, n *inNode) ([]interface{}, error) {
+	elems, err := inElems(target, n)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(elems))
+	for i, elem := range elems {
+		lit, ok := elem.(*literalNode)
+		if !ok || lit.val.kind == kindList || lit.val.kind == kindMap || lit.val.kind == kindAny {
+			return nil, errTranslate(target, elem, `elements of in must be bools, numbers or strings`)
+		}
+		values[i] = lit.val.interfaceValue()
+	}
+	return values, nil
+}
+
+var flippedOperators = map[operator]operator{
+	operatorEQ: operatorEQ, operatorNEQ: operatorNEQ, operatorGT: operatorLT,
+	operatorGTE: operatorLTE, operatorLT: operatorGT, operatorLTE: operatorGTE,
+}
+
+// fieldComparison splits the comparison n into a variable and a literal,
+// the operator is flipped if the literal is on the left, like `1 < $a` to `$a > 1`
+func fieldComparison(n *binaryNode) (*variableNode, operator, interface{}, bool) {
+	if !n.op.isCompare() || n.bools {
+		return nil, 0, nil, false
+	}
+	x, y, op := n.x, n.y, n.op
+	if _, ok := x.(*literalNode); ok {
+		x, y, op = y, x, flippedOperators[op]
+	}
+	v, ok := x.(*variableNode)
+	lit, ok2 := y.(*literalNode)
+	if !ok || !ok2 || lit.val.kind == kindList || lit.val.kind == kindMap || lit.val.kind == kindAny {
+		return nil, 0, nil, false
+	}
+	return v, op, lit.val.interfaceValue(), true
+}
+
+// boolCondition rewrites the comparison n of a bool variable and a bool literal into the variable or its negation,
+// like `$a = true` to `$a` and `$a != true` to `!$a`
+func boolCondition(n *binaryNode) (node, bool) {
+	if !n.bools || (n.op != operatorEQ && n.op != operatorNEQ) {
+		return nil, false
+	}
+	x, y := n.x, n.y
+	if _, ok := x.(*literalNode); ok {
+		x, y = y, x
+	}
+	v, ok := x.(*variableNode)
+	lit, ok2 := y.(*literalNode)
+	if !ok || !ok2 || lit.val.kind != kindBool {
+		return nil, false
+	}
+	if (n.op == operatorEQ) == lit.val.b {
+		return v, true
+	}
+	return &unaryNode{span: n.span, op: operatorNot, x: v}, true
+}
+
+// stringMatch splits calls of startsWith, endsWith and contains of a variable and a string literal
+func stringMatch(target string, n *callNode) (*variableNode, string, error) {
+	if n.name != `startsWith` && n.name != `endsWith` && n.name != `contains` {
+		return nil, ``, errTranslate(target, n, `func: `+n.name+` has no equivalent`)
+	}
+	v, ok := n.args[0].(*variableNode)
+	if !ok {
+		return nil, ``, errTranslate(target, n, `the string must be a variable`)
+	}
+	lit, ok := n.args[1].(*literalNode)
+	if !ok || lit.val.kind != kindString {
+		return nil, ``, errTranslate(target, n, `the pattern must be a string literal`)
+	}
+	return v, lit.val.s, nil
+}
+
+// flatten collects operands of nested and, or or, like `$a and ($b and $c)` to $a, $b and $c
+func flatten(n *binaryNode) []node {
+	var operands []node
+	for _, x := range []node{n.x, n.y} {
+		if b, ok := x.(*binaryNode); ok && b.op == n.op {
+			operands = append(operands, flatten(b)...)
+			continue
+		}
+		operands = append(operands, x)
+	}
+	return operands
+}
@@ -0,0 +1,104 @@
+package expr
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool(`update`, false, `update golden files of testdata`)
+
+// queryExprs are translated by every query translator, results are compared with golden files
+var queryExprs = []string{
+	`$age > 18`,
+	`18 <= $age`,
+	`$name = 'bob' and $age != 1.5`,
+	`$a = 1 or $b = 2 and $c = 3`,
+	`$a = 1 and ($b = 2 and $c = 3)`,
+	`!($a = 1 or $b = 2)`,
+	`$active and !$deleted`,
+	`$active = true and $deleted != true`,
+	`false = $active or $deleted != false`,
+	`$car in ('bmw', 'audi')`,
+	`$car not in ['bmw', 'audi']`,
+	`$key in {'a': 1, 'b': 2}`,
+	`startsWith($name, 'a.b')`,
+	`endsWith($name, '*.go') or contains($name, 'a?b')`,
+	`$user.address.city = 'x' and $age > 1 + 2`,
+	`1 < 2`,
+	`1 > 2`,
+}
+
+// checkGolden compares the JSON of queries with testdata/name, which is written by `go test -update`
+func checkGolden(t *testing.T, name string, queries map[string]interface{}) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(``, `  `)
+	require.Nil(t, encoder.Encode(queries))
+	got := b.String()
+	path := filepath.Join(`testdata`, name)
+	if *update {
+		require.Nil(t, ioutil.WriteFile(path, []byte(got), 0644))
+	}
+	want, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, string(want), got)
+}
+
+// known lists and maps of PartialEval are literals in residual programs
+func TestQueryResiduals(t *testing.T) {
+	program, err := Compile(`$x in $limits and $k not in $m`)
+	require.Nil(t, err)
+	residual, err := PartialEval(program, map[string]interface{}{
+		`limits`: []interface{}{1, 2}, `m`: map[string]interface{}{`b`: 1, `a`: 2},
+	})
+	require.Nil(t, err)
+	require.Equal(t, `$x in [1, 2] and $k not in {'a': 2, 'b': 1}`, residual.String())
+
+	where, args, err := residual.ToSQL()
+	require.Nil(t, err)
+	require.Equal(t, `x IN (?, ?) AND k NOT IN (?, ?)`, where)
+	require.Equal(t, []interface{}{int64(1), int64(2), `a`, `b`}, args)
+	q, err := residual.ToMongo()
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{`$and`: []interface{}{
+		map[string]interface{}{`x`: map[string]interface{}{`$in`: []interface{}{int64(1), int64(2)}}},
+		map[string]interface{}{`k`: map[string]interface{}{`$nin`: []interface{}{`a`, `b`}}},
+	}}, q)
+	q, err = residual.ToElastic()
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{`bool`: map[string]interface{}{`must`: []interface{}{
+		map[string]interface{}{`terms`: map[string]interface{}{`x`: []interface{}{int64(1), int64(2)}}},
+		elasticBool(`must_not`, map[string]interface{}{`terms`: map[string]interface{}{`k`: []interface{}{`a`, `b`}}}),
+	}}}, q)
+
+	residual, err = PartialEval(program, map[string]interface{}{`limits`: []interface{}{[]interface{}{1}}, `m`: map[string]interface{}{}})
+	require.Nil(t, err)
+	_, err = residual.ToMongo()
+	require.Contains(t, err.Error(), `elements of in must be bools, numbers or strings`)
+}
+
+func TestQueryFields(t *testing.T) {
+	fields := map[string]string{`user.age`: `age`, `name`: `name.keyword`}
+	q, err := ToElastic(`$user.age > 1 and $name = 'a'`, WithFields(fields))
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{`bool`: map[string]interface{}{`must`: []interface{}{
+		map[string]interface{}{`range`: map[string]interface{}{`age`: map[string]interface{}{`gt`: int64(1)}}},
+		map[string]interface{}{`term`: map[string]interface{}{`name.keyword`: `a`}},
+	}}}, q)
+
+	_, err = ToMongo(`$user.age > 1 and $car = 'a'`, WithFields(fields))
+	require.Contains(t, err.Error(), `mongo: can not translate $car, the variable isn't mapped to a field`)
+
+	q, err = ToMongo(`$tags[1] = 'a'`)
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{`tags.1`: map[string]interface{}{`$eq`: `a`}}, q)
+	_, err = ToElastic(`$tags[1] = 'a'`)
+	require.Contains(t, err.Error(), `elastic: can not translate $tags[1], variables with indexes must be mapped to fields`)
+}
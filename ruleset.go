@@ -0,0 +1,172 @@
+package expr
+
+import (
+	"context"
+	"sort"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// RuleSetMode selects which rules of a RuleSet are evaluated and reported
+type RuleSetMode uint8
+
+const (
+	// AllMatching evaluates every rule and reports the matched ones
+	AllMatching RuleSetMode = iota
+	// FirstMatch evaluates rules by priority and stops at the first matched one
+	FirstMatch
+	// CollectActions is AllMatching, and collects actions of the matched rules
+	CollectActions
+)
+
+// Rule is a named bool expression of a RuleSet
+type Rule struct {
+	Name string
+	Expr string
+	// rules of higher priorities are evaluated and reported first, rules of the same priority keep their order
+	Priority int
+	// actions collected by CollectActions once the rule matches
+	Actions []string
+	// anything attached to the rule, like descriptions and owners, it's reported as is
+	Metadata map[string]interface{}
+}
+
+// clone copies r, so rules of a RuleSet can't be changed by callers
+func (r Rule) clone() Rule {
+	if r.Actions != nil {
+		r.Actions = append([]string(nil), r.Actions...)
+	}
+	if r.Metadata != nil {
+		metadata := make(map[string]interface{}, len(r.Metadata))
+		for k, v := range r.Metadata {
+			metadata[k] = v
+		}
+		r.Metadata = metadata
+	}
+	return r
+}
+
+// RuleSet is rules compiled once and evaluated against the same variables in one pass,
+// identical pure sub-expressions and function calls of different rules are evaluated once per pass.
+// it's safe for concurrent use
+type RuleSet struct {
+	// sorted by priority
+	rules    []Rule
+	codes    []*bytecode
+	nslots   int
+	evalOpts []EvalOption
+}
+
+// RuleSetResult is the result of a RuleSet evaluation
+type RuleSetResult struct {
+	// copies of matched rules by priority
+	Matched []Rule
+	// actions of matched rules by priority without duplicates, they're only collected by CollectActions
+	Actions []string
+	// errors of rules which fail to evaluate by names, like missing variables, failed rules don't match
+	Errors map[string]error
+}
+
+// NewRuleSet compiles rules against the default Env, names of rules must be unique
+func NewRuleSet(rules ...Rule) (*RuleSet, error) {
+	return defaultEnv.NewRuleSet(rules...)
+}
+
+// NewRuleSet is like the package level NewRuleSet, but functions and options of e are used
+func (e *Env) NewRuleSet(rules ...Rule) (*RuleSet, error) {
+	sorted := make([]Rule, len(rules))
+	names := make(map[string]bool, len(rules))
+	for i := range rules {
+		r := rules[i]
+		if r.Name == `` {
+			return nil, zerror.BadRequest.Errorf(`rule: name of rule %d is empty`, i)
+		}
+		if names[r.Name] {
+			return nil, zerror.BadRequest.Errorf(`rule: %s is duplicate`, r.Name)
+		}
+		names[r.Name] = true
+		sorted[i] = r.clone()
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	roots := make([]node, len(sorted))
+	for i, r := range sorted {
+		program, err := e.Compile(r.Expr)
+		if err != nil {
+			return nil, zerror.BadRequest.Wrapf(err, `rule: %s`, r.Name)
+		}
+		roots[i] = program.root
+	}
+	codes, err := compileNodes(roots, e.NewParser().getFunc)
+	if err != nil {
+		return nil, err
+	}
+	rs := &RuleSet{rules: sorted, codes: codes, evalOpts: e.evalOpts}
+	if len(codes) > 0 {
+		rs.nslots = codes[0].nslots
+	}
+	return rs, nil
+}
+
+// Rules returns copies of the rules by priority
+func (rs *RuleSet) Rules() []Rule {
+	rules := make([]Rule, len(rs.rules))
+	for i, r := range rs.rules {
+		rules[i] = r.clone()
+	}
+	return rules
+}
+
+// Eval evaluates rules with vars in the mode
+func (rs *RuleSet) Eval(vars map[string]interface{}, mode RuleSetMode) (*RuleSetResult, error) {
+	return rs.EvalContext(context.Background(), vars, mode)
+}
+
+// EvalContext is like Eval, ctx and opts are like those of Program.EvalContext, the cost budget is for the whole pass.
+// errors of rules are reported in the result, it fails only if ctx is done or the budget is exceeded
+func (rs *RuleSet) EvalContext(ctx context.Context, vars map[string]interface{}, mode RuleSetMode, opts ...EvalOption) (*RuleSetResult, error) {
+	if mode > CollectActions {
+		return nil, zerror.BadRequest.Errorf(`rule: unknown mode: %d`, mode)
+	}
+	m := newVM(ctx, vars, nil, rs.evalOpts, opts)
+	defer m.release()
+	m.clearSlots(rs.nslots)
+	result := &RuleSetResult{}
+	var actions map[string]bool
+	for i, r := range rs.rules {
+		v, err := m.exec(rs.codes[i])
+		if err == nil && v.kind != kindBool {
+			err = zerror.BadRequest.Errorf(`expect bool result, got %s: %s`, v.kind, v)
+		}
+		if err != nil {
+			if Canceled.Cause(err) || BudgetExceeded.Cause(err) {
+				return nil, err
+			}
+			if result.Errors == nil {
+				result.Errors = map[string]error{}
+			}
+			result.Errors[r.Name] = err
+			continue
+		}
+		if !v.b {
+			continue
+		}
+		result.Matched = append(result.Matched, r.clone())
+		if mode == FirstMatch {
+			break
+		}
+		if mode == CollectActions {
+			if actions == nil {
+				actions = map[string]bool{}
+			}
+			for _, action := range r.Actions {
+				if !actions[action] {
+					actions[action] = true
+					result.Actions = append(result.Actions, action)
+				}
+			}
+		}
+	}
+	return result, nil
+}
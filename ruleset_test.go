@@ -0,0 +1,138 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleSet(t *testing.T) {
+	rules := []Rule{
+		{Name: `adult`, Expr: `$age >= 18`, Actions: []string{`allow`}},
+		{Name: `vip`, Expr: `$age >= 18 and $level > 3`, Priority: 10, Actions: []string{`discount`, `allow`}, Metadata: map[string]interface{}{`owner`: `sales`}},
+		{Name: `german car`, Expr: `$car in ('bmw', 'audi')`, Priority: 10, Actions: []string{`notify`}},
+		{Name: `blocked`, Expr: `$blocked`, Priority: 100, Actions: []string{`deny`}},
+	}
+	rs, err := NewRuleSet(rules...)
+	require.Nil(t, err)
+	names := func(rules []Rule) []string {
+		var names []string
+		for _, r := range rules {
+			names = append(names, r.Name)
+		}
+		return names
+	}
+	require.Equal(t, []string{`blocked`, `vip`, `german car`, `adult`}, names(rs.Rules()))
+
+	vars := map[string]interface{}{`age`: 20, `level`: 5, `car`: `bmw`, `blocked`: false}
+	result, err := rs.Eval(vars, AllMatching)
+	require.Nil(t, err)
+	require.Equal(t, []string{`vip`, `german car`, `adult`}, names(result.Matched))
+	require.Equal(t, `sales`, result.Matched[0].Metadata[`owner`])
+	// rules of the set can't be changed by callers
+	result.Matched[0].Name = `changed`
+	result.Matched[0].Actions[0] = `changed`
+	result.Matched[0].Metadata[`owner`] = `changed`
+	rs.Rules()[1].Actions[0] = `changed`
+	rules[1].Actions[1] = `changed`
+	require.Equal(t, []string{`blocked`, `vip`, `german car`, `adult`}, names(rs.Rules()))
+	require.Equal(t, []string{`discount`, `allow`}, rs.Rules()[1].Actions)
+	require.Equal(t, `sales`, rs.Rules()[1].Metadata[`owner`])
+	require.Nil(t, result.Actions)
+	require.Nil(t, result.Errors)
+
+	result, err = rs.Eval(vars, FirstMatch)
+	require.Nil(t, err)
+	require.Equal(t, []string{`vip`}, names(result.Matched))
+
+	result, err = rs.Eval(vars, CollectActions)
+	require.Nil(t, err)
+	require.Equal(t, []string{`discount`, `allow`, `notify`}, result.Actions)
+
+	_, err = rs.Eval(vars, RuleSetMode(3))
+	require.Contains(t, err.Error(), `rule: unknown mode: 3`)
+
+	// failed rules are reported and don't match
+	result, err = rs.Eval(map[string]interface{}{`age`: 20, `car`: `byd`, `blocked`: false}, AllMatching)
+	require.Nil(t, err)
+	require.Equal(t, []string{`adult`}, names(result.Matched))
+	require.Len(t, result.Errors, 1)
+	require.Contains(t, result.Errors[`vip`].Error(), `var: level not found`)
+
+	_, err = NewRuleSet(Rule{Name: `a`, Expr: `$a > 1`}, Rule{Name: `a`, Expr: `$a > 2`})
+	require.Contains(t, err.Error(), `rule: a is duplicate`)
+	_, err = NewRuleSet(Rule{Expr: `$a > 1`})
+	require.Contains(t, err.Error(), `rule: name of rule 0 is empty`)
+	_, err = NewRuleSet(Rule{Name: `a`, Expr: `$a > 1`}, Rule{Name: `b`, Expr: `$a +`})
+	require.Contains(t, err.Error(), `rule: b`)
+}
+
+func TestRuleSetSharing(t *testing.T) {
+	env := NewEnv()
+	called := map[string]int{}
+	require.Nil(t, env.RegisterFunc(`score`, func(s string) int64 {
+		called[`score`]++
+		return int64(len(s))
+	}, Pure()))
+	require.Nil(t, env.RegisterFunc(`random`, func() int64 {
+		called[`random`]++
+		return 4
+	}))
+	rs, err := env.NewRuleSet(
+		Rule{Name: `a`, Expr: `score($name) > 3`},
+		Rule{Name: `b`, Expr: `score($name) * 2 < 20 and random() > 1`},
+		Rule{Name: `c`, Expr: `score($name) * 2 > 50 or random() > 1`},
+		Rule{Name: `d`, Expr: `$x > 1 or score($name) * 2 > 5`},
+	)
+	require.Nil(t, err)
+	for i := 0; i < 2; i++ {
+		called = map[string]int{}
+		result, err := rs.Eval(map[string]interface{}{`name`: `alice`, `x`: 2}, AllMatching)
+		require.Nil(t, err)
+		require.Len(t, result.Matched, 4)
+		// impure functions are called every time
+		require.Equal(t, map[string]int{`score`: 1, `random`: 2}, called)
+	}
+
+	// the budget is for the whole pass
+	_, err = rs.EvalContext(context.Background(), map[string]interface{}{`name`: `alice`, `x`: 2}, AllMatching, WithCostBudget(10))
+	require.True(t, BudgetExceeded.Cause(err))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = rs.EvalContext(ctx, map[string]interface{}{`name`: `alice`, `x`: 2}, AllMatching)
+	require.True(t, Canceled.Cause(err))
+}
+
+func BenchmarkRuleSet(b *testing.B) {
+	var rules []Rule
+	for i := 0; i < 100; i++ {
+		rules = append(rules, Rule{
+			Name: fmt.Sprint(i),
+			Expr: fmt.Sprintf(`startsWith($car, 'b') and length($car) = 3 and $a * 2 + $b > %d`, i),
+		})
+	}
+	b.Run(`Evaluate`, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, r := range rules {
+				if _, err := Evaluate(r.Expr, benchVars); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	rs, err := NewRuleSet(rules...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run(`RuleSet`, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := rs.Eval(benchVars, AllMatching); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
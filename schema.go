@@ -0,0 +1,211 @@
+package expr
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/EchoUtopia/expr/ast"
+	"github.com/EchoUtopia/zerror"
+)
+
+// Schema declares the types of variables, a nil type is only known at runtime.
+// parsing with a schema rejects unknown variables, and checks the types of variables like the types of literals
+type Schema map[string]reflect.Type
+
+// with returns a copy of s with the variables of other
+func (s Schema) with(other Schema) Schema {
+	c := make(Schema, len(s)+len(other))
+	for name, t := range s {
+		c[name] = t
+	}
+	for name, t := range other {
+		c[name] = t
+	}
+	return c
+}
+
+func (s Schema) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	return names
+}
+
+// SchemaOf derives a schema from v, a struct or a map with string keys.
+// fields are named like paths of variables, values of maps are samples whose dynamic types are declared,
+// nested map[string]interface{} and []interface{} samples are typed by their elements like SchemaFromJSON
+func SchemaOf(v interface{}) (Schema, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	s := Schema{}
+	switch {
+	case rv.Kind() == reflect.Struct:
+		for name, i := range structFields(rv.Type()) {
+			s[name] = rv.Type().Field(i).Type
+		}
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		iter := rv.MapRange()
+		for iter.Next() {
+			s[iter.Key().String()] = sampleType(iter.Value().Interface())
+		}
+	default:
+		return nil, zerror.BadRequest.Errorf(`schema: expect struct or map with string keys, got %T`, v)
+	}
+	return s, nil
+}
+
+// SchemaFromJSON derives a schema from a sample json object, types are decoded types like json.Unmarshal decodes into interface{}:
+// numbers are float64, objects are typed by their fields, arrays by their first elements, null is only known at runtime
+func SchemaFromJSON(sample []byte) (Schema, error) {
+	var m map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(sample))
+	if err := d.Decode(&m); err != nil {
+		return nil, zerror.BadRequest.Wrapf(err, `schema: invalid json object`)
+	}
+	return SchemaOf(m)
+}
+
+// sampleType is the type of sample, objects decoded from json are structs whose fields are tagged by the keys,
+// so paths of variables are resolved like other structs
+func sampleType(sample interface{}) reflect.Type {
+	switch sample := sample.(type) {
+	case nil:
+		return emptyInterfaceType
+	case map[string]interface{}:
+		keys := make([]string, 0, len(sample))
+		for key := range sample {
+			keys = append(keys, key)
+		}
+		if len(keys) == 0 {
+			return reflect.TypeOf(sample)
+		}
+		sort.Strings(keys)
+		fields := make([]reflect.StructField, len(keys))
+		for i, key := range keys {
+			fields[i] = reflect.StructField{
+				Name: `F` + strconv.Itoa(i),
+				Type: sampleType(sample[key]),
+				Tag:  reflect.StructTag(`expr:` + strconv.Quote(key)),
+			}
+		}
+		return reflect.StructOf(fields)
+	case []interface{}:
+		var elem reflect.Type
+		for _, e := range sample {
+			t := sampleType(e)
+			if elem != nil && t != elem {
+				return reflect.TypeOf(sample)
+			}
+			elem = t
+		}
+		if elem == nil {
+			return reflect.TypeOf(sample)
+		}
+		return reflect.SliceOf(elem)
+	}
+	return reflect.TypeOf(sample)
+}
+
+// ParseWithSchema is like ParseAST, but variables are checked against schema and the expression must result in bool
+func ParseWithSchema(input string, schema Schema) (ast.Node, error) {
+	parser := NewParser()
+	parser.SetSchema(schema)
+	tree, err := parser.ParseWithCache(input)
+	if err != nil {
+		return nil, err
+	}
+	return tree.AST(), nil
+}
+
+// SetSchema sets the schema which variables are checked against, it takes precedence over the schema of the Env of l
+func (l *listenerForParse) SetSchema(s Schema) {
+	l.schema = s
+}
+
+func (l *listenerForParse) getSchema() Schema {
+	if l.schema != nil {
+		return l.schema
+	}
+	return l.env.schema
+}
+
+// typeAt returns the type of the variable at path, nil if it's only known at runtime
+func typeAt(t reflect.Type, name string, path []pathStep) (reflect.Type, error) {
+	for i, step := range path {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() == reflect.Interface {
+			return nil, nil
+		}
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			if !step.isIndex {
+				return nil, zerror.BadRequest.Errorf(`%s: can not get field of %s`, pathString(name, path[:i+1]), t)
+			}
+			if t.Kind() == reflect.Array && step.index >= t.Len() {
+				return nil, zerror.BadRequest.Errorf(`%s: index out of range with length %d`, pathString(name, path[:i+1]), t.Len())
+			}
+			t = t.Elem()
+		case reflect.Map:
+			if step.isIndex || t.Key().Kind() != reflect.String {
+				return nil, zerror.BadRequest.Errorf(`%s: can not index %s`, pathString(name, path[:i+1]), t)
+			}
+			t = t.Elem()
+		case reflect.Struct:
+			if step.isIndex {
+				return nil, zerror.BadRequest.Errorf(`%s: can not index %s`, pathString(name, path[:i+1]), t)
+			}
+			fields := structFields(t)
+			index, ok := fields[step.field]
+			if !ok {
+				return nil, errFieldNotFound(name, path[:i+1], fields)
+			}
+			t = t.Field(index).Type
+		default:
+			return nil, zerror.BadRequest.Errorf(`%s: can not get %s of %s`, pathString(name, path[:i+1]), step, t)
+		}
+	}
+	return t, nil
+}
+
+// errFieldNotFound suggests the closest fields of the struct
+func errFieldNotFound(name string, path []pathStep, fields map[string]int) error {
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	suggestions := suggest(path[len(path)-1].field, names)
+	for i, field := range suggestions {
+		suggestions[i] = pathString(name, append(path[:len(path)-1:len(path)-1], pathStep{field: field}))
+	}
+	return errNotFound(`var`, pathString(name, path), suggestions)
+}
+
+// typeValue is the value of t which the checker infers, like values of literals,
+// types which aren't values of expressions, like structs, are kept, see isOpaque
+func typeValue(t reflect.Type) reflect.Value {
+	if t == nil {
+		return reflectArgVal
+	}
+	et := exprType(t)
+	if et == emptyInterfaceType {
+		return reflectArgVal
+	}
+	if et == nil {
+		return reflect.Zero(t)
+	}
+	return reflect.Zero(et)
+}
+
+// isOpaque reports if v is of a type which isn't a value of expressions, like time.Time,
+// it can't be compared or computed, only passed to parameters of interface{}
+func isOpaque(v reflect.Value) bool {
+	return v.Type() != reflectArgType && !isNumberArg(v) && exprType(v.Type()) == nil
+}
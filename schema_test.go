@@ -0,0 +1,113 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EchoUtopia/expr/ast"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaAddress struct {
+	City string `expr:"city"`
+	Zip  int    `expr:"-"`
+}
+
+type schemaUser struct {
+	Name    string
+	Age     int
+	Score   float32
+	Admin   bool
+	Tags    []string
+	Address *schemaAddress `expr:"address"`
+	Born    time.Time
+	Extra   interface{}
+	Attrs   map[string]int
+	secret  int
+}
+
+func TestSchemaOf(t *testing.T) {
+	s, err := SchemaOf(&schemaUser{})
+	require.Nil(t, err)
+	require.Equal(t, reflect.TypeOf(0), s[`Age`])
+	require.Equal(t, reflect.TypeOf(&schemaAddress{}), s[`address`])
+	require.NotContains(t, s, `secret`)
+
+	s, err = SchemaOf(map[string]interface{}{`id`: int64(1), `tags`: []interface{}{`a`}, `any`: nil})
+	require.Nil(t, err)
+	require.Equal(t, Schema{`id`: reflect.TypeOf(int64(0)), `tags`: reflect.TypeOf([]string{}), `any`: emptyInterfaceType}, s)
+
+	_, err = SchemaOf(1)
+	require.NotNil(t, err)
+	_, err = SchemaFromJSON([]byte(`[1]`))
+	require.NotNil(t, err)
+}
+
+func TestParseWithSchema(t *testing.T) {
+	s, err := SchemaOf(schemaUser{})
+	require.Nil(t, err)
+	valid := []string{
+		`$Age > 10`, `$Score * 2 > $Age`, `$Admin and $Name = 'a'`, `$address.city = 'x'`, `$Tags[0] = 'a'`, `'a' in $Tags`,
+		`startsWith($Name, 'a')`, `$Extra.x.y = 1`, `$Attrs.x > 1`, `$Age in (1, 2)`,
+	}
+	for _, input := range valid {
+		_, err := ParseWithSchema(input, s)
+		require.Nil(t, err, input)
+	}
+	n, err := ParseWithSchema(`$Age > 10 and $Name = 'a'`, s)
+	require.Nil(t, err)
+	require.Equal(t, `$Age > 10 and $Name = 'a'`, ast.Format(n))
+	invalid := map[string]string{
+		`$Age > 'ten'`:            `line 1:0 can not compare between (int64)$Age and (string)'ten'`,
+		`$Admin and $Age`:         `line 1:11 expect var: $Age bool, got int64`,
+		`$Nam = 'a'`:              `line 1:0 var: Nam not found, did you mean Name?`,
+		`$address.cty = 'x'`:      `line 1:0 var: $address.cty not found, did you mean $address.city?`,
+		`$address.Zip = 1`:        `line 1:0 var: $address.Zip not found`,
+		`$Tags.x = 1`:             `line 1:0 $Tags.x: can not get field of []string`,
+		`startsWith($Age, 'a')`:   `line 1:11 func: startsWith, arg position: 0 expect string, got int64`,
+		`$Name - 1 > 0`:           `line 1:0 can not do math operation with type: (string)$Name`,
+		`$Age + 1`:                `line 1:0 expect bool expression, got int64`,
+		`$Age in ('a', 'b')`:      `line 1:0 invalid expression: $Agein('a','b'), left operand type: int64`,
+		`$Born = 1`:               `line 1:0 can not compare type: (time.Time)$Born`,
+		`$Born + 1 > 2`:           `line 1:0 can not do math operation with type: (struct)$Born`,
+		`$address in ('a')`:       `line 1:0 invalid expression: $addressin('a'), left operand type: *expr.schemaAddress`,
+		`$secret = 1 and $Tags.x`: "line 1:0 var: secret not found\nline 1:16 $Tags.x: can not get field of []string",
+	}
+	for input, msg := range invalid {
+		_, err := ParseWithSchema(input, s)
+		require.EqualError(t, err, msg, input)
+	}
+
+	// structs can only be passed to parameters of interface{}
+	env := NewEnv(WithSchema(s))
+	require.Nil(t, env.RegisterFunc(`isZero`, func(v interface{}) bool { return reflect.ValueOf(v).IsZero() }))
+	_, err = env.Compile(`isZero($Born) and !isZero($address)`)
+	require.Nil(t, err)
+	_, err = env.Compile(`startsWith($Born, 'a')`)
+	require.EqualError(t, err, `line 1:11 func: startsWith, arg position: 0 expect string, got time.Time`)
+	result, err := env.Evaluate(`isZero($Born)`, map[string]interface{}{`Born`: time.Time{}})
+	require.Nil(t, err)
+	require.True(t, result)
+}
+
+func TestSchemaFromJSON(t *testing.T) {
+	s, err := SchemaFromJSON([]byte(`{"user": {"id": 1, "name": "x", "tags": ["a"], "mixed": [1, "a"], "geo": {"lat": 1.5}, "nick": null}, "ok": true}`))
+	require.Nil(t, err)
+	env := NewEnv(WithSchema(s))
+	for _, input := range []string{`$user.id > 1 and $ok`, `$user.geo.lat > 1.5`, `$user.tags[0] = 'a'`, `$user.mixed[0] = 1`, `$user.nick.first = 'a'`} {
+		_, err := env.Compile(input)
+		require.Nil(t, err, input)
+	}
+	_, err = env.Compile(`$user.id = 'a'`)
+	require.EqualError(t, err, `line 1:0 can not compare between (float64)$user.id and (string)'a'`)
+	_, err = env.Compile(`$user.geo.lta > 1`)
+	require.EqualError(t, err, `line 1:0 var: $user.geo.lta not found, did you mean $user.geo.lat?`)
+
+	// the checked expression is evaluated with the decoded sample
+	result, err := env.Evaluate(`$user.geo.lat > 1 and $user.tags[0] = 'a'`, map[string]interface{}{
+		`user`: map[string]interface{}{`geo`: map[string]interface{}{`lat`: 1.5}, `tags`: []interface{}{`a`}},
+	})
+	require.Nil(t, err)
+	require.True(t, result)
+}
@@ -0,0 +1,270 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+)
+
+type sqlOptions struct {
+	columns     map[string]string
+	placeholder func(i int) string
+}
+
+// SQLOption configures ToSQL
+type SQLOption func(*sqlOptions)
+
+// WithColumns maps variables to columns, keys are variables without `$` like `user.age`, columns are written as is.
+// with columns, variables out of them can't be translated, without columns, variables without paths are columns of the same names
+func WithColumns(columns map[string]string) SQLOption {
+	return func(o *sqlOptions) {
+		o.columns = columns
+	}
+}
+
+// WithDollarPlaceholders writes bind parameters as $1, $2... like Postgres, they're ? by default like SQLite and MySQL
+func WithDollarPlaceholders() SQLOption {
+	return func(o *sqlOptions) {
+		o.placeholder = func(i int) string {
+			return `$` + strconv.Itoa(i)
+		}
+	}
+}
+
+// precedences of SQL operators, the higher binds tighter
+const (
+	sqlPrecOr = iota + 1
+	sqlPrecAnd
+	sqlPrecNot
+	sqlPrecCompare
+	sqlPrecAdd
+	sqlPrecMul
+	sqlPrecNeg
+	sqlPrecAtom
+)
+
+var sqlOperators = map[operator]struct {
+	text string
+	prec int
+}{
+	operatorOr: {`OR`, sqlPrecOr}, operatorAnd: {`AND`, sqlPrecAnd},
+	operatorEQ: {`=`, sqlPrecCompare}, operatorNEQ: {`<>`, sqlPrecCompare}, operatorGT: {`>`, sqlPrecCompare},
+	operatorGTE: {`>=`, sqlPrecCompare}, operatorLT: {`<`, sqlPrecCompare}, operatorLTE: {`<=`, sqlPrecCompare},
+	operatorAdd: {`+`, sqlPrecAdd}, operatorSub: {`-`, sqlPrecAdd},
+	operatorMul: {`*`, sqlPrecMul}, operatorDiv: {`/`, sqlPrecMul}, operatorMod: {`%`, sqlPrecMul},
+}
+
+// patterns of LIKE which startsWith, endsWith and contains are translated to, %s is the escaped argument
+var sqlLikePatterns = map[string]string{
+	`startsWith`: `%s%`,
+	`endsWith`:   `%%s`,
+	`contains`:   `%%s%`,
+}
+
+// reservedSQLWords can't be columns unless they're quoted, which differs by databases, so variables of them must be mapped
+var reservedSQLWords = func() map[string]bool {
+	words := map[string]bool{}
+	for _, word := range strings.Fields(`ADD ALL ALTER ANALYZE AND ANY ARRAY AS ASC BETWEEN BOTH BY CASE CAST CHECK COLLATE COLUMN
+		CONSTRAINT CREATE CROSS CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP CURRENT_USER DEFAULT DELETE DESC DISTINCT DIV DO
+		DROP ELSE END ESCAPE EXCEPT EXISTS FALSE FETCH FOR FOREIGN FROM FULL GRANT GROUP HAVING IN INDEX INNER INSERT INTERSECT
+		INTERVAL INTO IS JOIN KEY LATERAL LEADING LEFT LIKE LIMIT MOD NATURAL NOT NULL OFFSET ON ONLY OR ORDER OUTER OVER
+		PRIMARY RANGE REFERENCES RETURNING RIGHT ROW ROWS SELECT SET SOME TABLE THEN TO TRAILING TRUE UNION UNIQUE UPDATE
+		USER USING VALUES WHEN WHERE WINDOW WITH`) {
+		words[word] = true
+	}
+	return words
+}()
+
+// ToSQL translates expr into a condition of SQL WHERE clauses with bind parameters, like `age > ? AND car IN (?, ?)`.
+// literals are bound, `in` with lists and maps is IN, startsWith, endsWith and contains of string literals are LIKE escaped by `!`.
+// variables are columns of the same names, reserved words, like $order, fail unless they're mapped by WithColumns.
+// SQL differs on NULL columns, which are missing variables failing Evaluate,
+// and LIKE is case insensitive in SQLite by default
+func ToSQL(expr string, opts ...SQLOption) (string, []interface{}, error) {
+	program, err := Compile(expr)
+	if err != nil {
+		return ``, nil, err
+	}
+	return program.ToSQL(opts...)
+}
+
+// ToSQL translates the program like the package level ToSQL, constants are folded before translating
+func (p *Program) ToSQL(opts ...SQLOption) (string, []interface{}, error) {
+	w := &sqlWriter{sqlOptions: sqlOptions{placeholder: func(int) string { return `?` }}}
+	for _, opt := range opts {
+		opt(&w.sqlOptions)
+	}
+	if err := w.write(p.root, sqlPrecOr, true); err != nil {
+		return ``, nil, err
+	}
+	return w.b.String(), w.args, nil
+}
+
+type sqlWriter struct {
+	sqlOptions
+	b    strings.Builder
+	args []interface{}
+}
+
+// write writes n, parenthesized if it binds looser than prec, isBool is true for conditions
+func (w *sqlWriter) write(n node, prec int, isBool bool) error {
+	if p := sqlPrecedence(n); p < prec {
+		w.b.WriteString(`(`)
+		defer w.b.WriteString(`)`)
+	}
+	switch n := n.(type) {
+	case *literalNode:
+		if isBool && n.val.kind == kindBool {
+			w.b.WriteString(strings.ToUpper(strconv.FormatBool(n.val.b)))
+			return nil
+		}
+		return w.bind(n, n.val)
+	case *variableNode:
+		column, err := w.column(n)
+		if err != nil {
+			return err
+		}
+		w.b.WriteString(column)
+		return nil
+	case *unaryNode:
+		if n.op == operatorNot {
+			w.b.WriteString(`NOT `)
+			return w.write(n.x, sqlPrecNot, true)
+		}
+		w.b.WriteString(`-`)
+		return w.write(n.x, sqlPrecNeg+1, false)
+	case *binaryNode:
+		return w.writeBinary(n)
+	case *inNode:
+		return w.writeIn(n)
+	case *callNode:
+		return w.writeCall(n)
+	case *identifierNode:
+		return errTranslate(`sql`, n, `identifiers must be bound`)
+	case *listNode, *mapNode:
+		return errTranslate(`sql`, n, `lists and maps are only supported after in`)
+	}
+	return errTranslate(`sql`, n, `unknown node`)
+}
+
+func sqlPrecedence(n node) int {
+	switch n := n.(type) {
+	case *unaryNode:
+		if n.op == operatorNot {
+			return sqlPrecNot
+		}
+		return sqlPrecNeg
+	case *binaryNode:
+		if op, ok := sqlOperators[n.op]; ok {
+			return op.prec
+		}
+	case *inNode:
+		return sqlPrecCompare
+	case *callNode:
+		if _, ok := sqlLikePatterns[n.name]; ok {
+			return sqlPrecCompare
+		}
+	}
+	return sqlPrecAtom
+}
+
+func (w *sqlWriter) bind(n node, v value) error {
+	switch v.kind {
+	case kindBool, kindInt, kindFloat, kindString:
+	default:
+		return errTranslate(`sql`, n, `only bools, numbers and strings can be bound`)
+	}
+	w.args = append(w.args, v.interfaceValue())
+	w.b.WriteString(w.placeholder(len(w.args)))
+	return nil
+}
+
+func (w *sqlWriter) column(n *variableNode) (string, error) {
+	name := pathString(n.name, n.path)[1:]
+	if w.columns == nil {
+		if len(n.path) > 0 {
+			return ``, errTranslate(`sql`, n, `variables with paths must be mapped to columns`)
+		}
+		if reservedSQLWords[strings.ToUpper(name)] {
+			return ``, errTranslate(`sql`, n, name+` is a reserved word, it must be mapped to a quoted column`)
+		}
+		return name, nil
+	}
+	column, ok := w.columns[name]
+	if !ok {
+		return ``, errTranslate(`sql`, n, `the variable isn't mapped to a column`)
+	}
+	return column, nil
+}
+
+func (w *sqlWriter) writeBinary(n *binaryNode) error {
+	op, ok := sqlOperators[n.op]
+	if !ok {
+		return errTranslate(`sql`, n, n.op.String()+` has no SQL equivalent`)
+	}
+	isBool := n.op == operatorAnd || n.op == operatorOr
+	// and and or are associative, other operators are parenthesized on the right to keep the order of evaluation
+	left, right := op.prec, op.prec+1
+	if isBool {
+		right = op.prec
+	} else if op.prec == sqlPrecCompare {
+		left = op.prec + 1
+	}
+	if err := w.write(n.x, left, isBool || n.bools); err != nil {
+		return err
+	}
+	w.b.WriteString(` ` + op.text + ` `)
+	return w.write(n.y, right, isBool || n.bools)
+}
+
+func (w *sqlWriter) writeIn(n *inNode) error {
+	elems, err := inElems(`sql`, n)
+	if err != nil {
+		return err
+	}
+	if len(elems) == 0 {
+		// IN () is invalid, nothing is in an empty collection
+		w.b.WriteString(strings.ToUpper(strconv.FormatBool(n.not)))
+		return nil
+	}
+	if err := w.write(n.x, sqlPrecCompare+1, false); err != nil {
+		return err
+	}
+	if n.not {
+		w.b.WriteString(` NOT IN (`)
+	} else {
+		w.b.WriteString(` IN (`)
+	}
+	for i, elem := range elems {
+		if i > 0 {
+			w.b.WriteString(`, `)
+		}
+		if err := w.write(elem, sqlPrecOr, false); err != nil {
+			return err
+		}
+	}
+	w.b.WriteString(`)`)
+	return nil
+}
+
+func (w *sqlWriter) writeCall(n *callNode) error {
+	pattern, ok := sqlLikePatterns[n.name]
+	if !ok {
+		return errTranslate(`sql`, n, `func: `+n.name+` has no SQL equivalent`)
+	}
+	lit, ok := n.args[1].(*literalNode)
+	if !ok || lit.val.kind != kindString {
+		return errTranslate(`sql`, n, `the pattern must be a string literal`)
+	}
+	if err := w.write(n.args[0], sqlPrecCompare+1, false); err != nil {
+		return err
+	}
+	w.b.WriteString(` LIKE `)
+	w.args = append(w.args, strings.Replace(pattern, `%s`, escapeLike(lit.val.s), 1))
+	w.b.WriteString(w.placeholder(len(w.args)) + ` ESCAPE '!'`)
+	return nil
+}
+
+// escapeLike escapes wildcards of LIKE by `!`, `\` would escape the closing quote of ESCAPE in MySQL
+func escapeLike(s string) string {
+	return strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(s)
+}
@@ -0,0 +1,80 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSQL(t *testing.T) {
+	cases := []struct {
+		expr  string
+		where string
+		args  []interface{}
+	}{
+		{`$age > 18`, `age > ?`, []interface{}{int64(18)}},
+		{`$age >= 18 and $car in ('bmw', 'audi')`, `age >= ? AND car IN (?, ?)`, []interface{}{int64(18), `bmw`, `audi`}},
+		{`$a = 1 or $b != 'x' and $c < 1.5`, `a = ? OR b <> ? AND c < ?`, []interface{}{int64(1), `x`, 1.5}},
+		{`($a = 1 or $b = 2) and $c = 3`, `(a = ? OR b = ?) AND c = ?`, []interface{}{int64(1), int64(2), int64(3)}},
+		{`!($a = 1 or $b = 2)`, `NOT (a = ? OR b = ?)`, []interface{}{int64(1), int64(2)}},
+		{`$active and !$deleted`, `active AND NOT deleted`, nil},
+		{`$a - ($b - $c) > $d * ($e + 1)`, `a - (b - c) > d * (e + ?)`, []interface{}{int64(1)}},
+		{`-($a + 1) < 0`, `-(a + ?) < ?`, []interface{}{int64(1), int64(0)}},
+		{`$x not in ['a', 'b']`, `x NOT IN (?, ?)`, []interface{}{`a`, `b`}},
+		{`$x in {'a': 1, 'b': 2}`, `x IN (?, ?)`, []interface{}{`a`, `b`}},
+		{`startsWith($name, 'a%b')`, `name LIKE ? ESCAPE '!'`, []interface{}{`a!%b%`}},
+		{`endsWith($name, 'x_y') or contains($name, 'c\\d!')`, `name LIKE ? ESCAPE '!' OR name LIKE ? ESCAPE '!'`, []interface{}{`%x!_y`, `%c\d!!%`}},
+		{`!contains($name, 'a')`, `NOT name LIKE ? ESCAPE '!'`, []interface{}{`%a%`}},
+		{`$a > 1 + 2 and true`, `a > ?`, []interface{}{int64(3)}},
+		{`1 < 2`, `TRUE`, nil},
+		{`$x not in {}`, `TRUE`, nil},
+	}
+	for _, c := range cases {
+		where, args, err := ToSQL(c.expr)
+		require.Nil(t, err, c.expr)
+		require.Equal(t, c.where, where, c.expr)
+		require.Equal(t, c.args, args, c.expr)
+	}
+}
+
+func TestToSQLOptions(t *testing.T) {
+	columns := map[string]string{`user.age`: `u.age`, `user.tags[0]`: `u.first_tag`, `car`: `"car"`}
+	where, args, err := ToSQL(`$user.age > 18 and ($car = 'bmw' or $user.tags[0] = 'a')`, WithColumns(columns), WithDollarPlaceholders())
+	require.Nil(t, err)
+	require.Equal(t, `u.age > $1 AND ("car" = $2 OR u.first_tag = $3)`, where)
+	require.Equal(t, []interface{}{int64(18), `bmw`, `a`}, args)
+
+	_, _, err = ToSQL(`$user.age > 18 and $name = 'a'`, WithColumns(columns))
+	require.Contains(t, err.Error(), `sql: can not translate $name, the variable isn't mapped to a column`)
+
+	where, _, err = ToSQL(`$order > 1 and $select = 'x'`, WithColumns(map[string]string{`order`: `"order"`, `select`: `"select"`}))
+	require.Nil(t, err)
+	require.Equal(t, `"order" > ? AND "select" = ?`, where)
+
+	program, err := Compile(`$age > $limit`)
+	require.Nil(t, err)
+	partial, err := PartialEval(program, map[string]interface{}{`limit`: 18})
+	require.Nil(t, err)
+	where, args, err = partial.ToSQL()
+	require.Nil(t, err)
+	require.Equal(t, `age > ?`, where)
+	require.Equal(t, []interface{}{int64(18)}, args)
+}
+
+func TestToSQLErrors(t *testing.T) {
+	cases := map[string]string{
+		`$a ** 2 > 1`:                  `sql: can not translate $a ** 2, ** has no SQL equivalent`,
+		`$user.age > 1`:                `sql: can not translate $user.age, variables with paths must be mapped to columns`,
+		`'a' in $tags`:                 `sql: can not translate 'a' in $tags, in is only supported with lists and maps`,
+		`startsWith($a, $b)`:           `sql: can not translate startsWith($a, $b), the pattern must be a string literal`,
+		`length($a) > 1`:               `sql: can not translate length($a), func: length has no SQL equivalent`,
+		`x = 1`:                        `sql: can not translate x, identifiers must be bound`,
+		`$order > 1 and $select = 'x'`: `sql: can not translate $order, order is a reserved word, it must be mapped to a quoted column`,
+	}
+	for input, msg := range cases {
+		_, _, err := ToSQL(input)
+		require.Contains(t, err.Error(), msg, input)
+	}
+	_, _, err := ToSQL(`$a >`)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,85 @@
+package expr
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// the key of suggestions in the data of zerror, ExprError takes them as Suggestions
+const dataSuggestions = `suggestions`
+
+// at most this many names are suggested
+const maxSuggestions = 3
+
+// errNotFound is the error of unknown functions and variables, like `func: startWith not found, did you mean startsWith?`
+func errNotFound(kind, name string, suggestions []string) error {
+	if len(suggestions) == 0 {
+		return zerror.BadRequest.Errorf(`%s: %s not found`, kind, name)
+	}
+	return zerror.BadRequest.Errorf(`%s: %s not found, did you mean %s?`, kind, name, strings.Join(suggestions, ` or `)).
+		WithData(zerror.Data{dataSuggestions: suggestions})
+}
+
+// suggest returns the candidates closest to name by edit distance, ignoring case.
+// a third of the length of name can be edited, at least one character
+func suggest(name string, candidates []string) []string {
+	max := len(name) / 3
+	if max < 1 {
+		max = 1
+	}
+	lower := strings.ToLower(name)
+	distances := map[string]int{}
+	var names []string
+	for _, c := range candidates {
+		if _, ok := distances[c]; ok || c == name {
+			continue
+		}
+		if d := editDistance(lower, strings.ToLower(c)); d <= max {
+			distances[c] = d
+			names = append(names, c)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		di, dj := distances[names[i]], distances[names[j]]
+		return di < dj || di == dj && names[i] < names[j]
+	})
+	if len(names) > maxSuggestions {
+		names = names[:maxSuggestions]
+	}
+	return names
+}
+
+// editDistance counts insertions, deletions, substitutions and transpositions of adjacent bytes turning a into b
+func editDistance(a, b string) int {
+	// rows of the distance matrix, two before the current one are needed by transpositions
+	prev2, prev, cur := make([]int, len(b)+1), make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func minInt(x int, ys ...int) int {
+	for _, y := range ys {
+		if y < x {
+			x = y
+		}
+	}
+	return x
+}
@@ -0,0 +1,56 @@
+package expr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggest(t *testing.T) {
+	require.Equal(t, 0, editDistance(`abc`, `abc`))
+	require.Equal(t, 1, editDistance(`startWith`, `startsWith`))
+	require.Equal(t, 1, editDistance(`lenght`, `length`))
+	require.Equal(t, 3, editDistance(``, `abc`))
+	require.Equal(t, 3, editDistance(`kitten`, `sitting`))
+
+	candidates := []string{`startsWith`, `endsWith`, `contains`, `toLower`, `toUpper`, `length`}
+	require.Equal(t, []string{`startsWith`}, suggest(`startWith`, candidates))
+	require.Equal(t, []string{`startsWith`}, suggest(`STARTSWITH`, candidates))
+	require.Equal(t, []string{`toLower`}, suggest(`toLowr`, candidates))
+	require.Equal(t, []string{`length`}, suggest(`lenght`, candidates))
+	require.Empty(t, suggest(`foo`, candidates))
+	require.Equal(t, []string{`bat`, `car`, `cut`}, suggest(`cat`, []string{`dog`, `cut`, `car`, `bat`, `cars`}))
+}
+
+func TestSuggestFunction(t *testing.T) {
+	_, err := Parse(`startWith($name, 'a')`)
+	require.EqualError(t, err, `line 1:0 func: startWith not found, did you mean startsWith?`)
+	var e *ExprError
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, CategoryFunction, e.Category)
+	require.Equal(t, []string{`startsWith`}, e.Suggestions)
+	require.Equal(t, 9, e.End)
+
+	_, err = Parse(`nothingLikeIt()`)
+	require.EqualError(t, err, `line 1:0 func: nothingLikeIt not found`)
+	require.True(t, errors.As(err, &e))
+	require.Empty(t, e.Suggestions)
+}
+
+func TestSuggestVariable(t *testing.T) {
+	env := NewEnv(WithKnownVars(`user_id`, `user_name`, `age`))
+	_, err := env.NewParser().Parse(`$usr_id = 1 and $age > 18 and $agee < 60 and $user_name.first = 'a'`)
+	errs, ok := err.(ExprErrors)
+	require.True(t, ok, err)
+	require.Len(t, errs, 2)
+	require.Equal(t, CategoryVariable, errs[0].Category)
+	require.Equal(t, `var: usr_id not found, did you mean user_id?`, errs[0].Msg)
+	require.Equal(t, []string{`user_id`}, errs[0].Suggestions)
+	require.Equal(t, "$usr_id = 1 and $age > 18 and $agee < 60 and $user_name.first = 'a'\n^^^^^^^", errs[0].Snippet())
+	require.Equal(t, []string{`age`}, errs[1].Suggestions)
+
+	// without known vars, variables are suggested from the vars of the evaluation
+	_, err = Evaluate(`$usr_id = 1`, map[string]interface{}{`user_id`: 1, `age`: 18})
+	require.Contains(t, err.Error(), `var: usr_id not found, did you mean user_id?`)
+}
@@ -0,0 +1,26 @@
+package expr
+
+import (
+	"github.com/EchoUtopia/expr/ast"
+)
+
+// ParseAST parses input with the functions of the default Env and returns its syntax tree,
+// see ast.Format to turn it back into text
+func ParseAST(input string) (ast.Node, error) {
+	return NewParser().ParseAST(input)
+}
+
+// ParseAST is like ParseValueWithCache, but returns the syntax tree, positions are byte offsets in input.
+// the tree is not optimized, lists and maps of literals are still List and Map
+func (l *listenerForParse) ParseAST(input string) (ast.Node, error) {
+	tree, err := l.ParseValueWithCache(input)
+	if err != nil {
+		return nil, err
+	}
+	return tree.AST(), nil
+}
+
+// AST returns the optimized syntax tree of p, which is what p evaluates
+func (p *Program) AST() ast.Node {
+	return toAST(p.root)
+}
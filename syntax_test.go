@@ -0,0 +1,40 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/EchoUtopia/expr/ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAST(t *testing.T) {
+	input := `$a.b > 1 + 2 and startsWith($c, 'x')`
+	n, err := ParseAST(input)
+	require.Nil(t, err)
+	require.Equal(t, `$a.b > 1 + 2 and startsWith($c, 'x')`, ast.Format(n))
+
+	var vars []string
+	ast.Inspect(n, func(n ast.Node) bool {
+		if v, ok := n.(*ast.Var); ok {
+			vars = append(vars, input[v.Pos():v.End()])
+		}
+		return true
+	})
+	require.Equal(t, []string{`$a.b`, `$c`}, vars)
+
+	and := n.(*ast.BinaryOp)
+	require.Equal(t, ast.And, and.Op)
+	require.Equal(t, `1 + 2`, input[and.X.(*ast.BinaryOp).Y.Pos():and.X.(*ast.BinaryOp).Y.End()])
+	require.Equal(t, `startsWith($c, 'x')`, input[and.Y.Pos():and.Y.End()])
+
+	tree, err := Parse(input)
+	require.Nil(t, err)
+	require.Equal(t, ast.Format(n), ast.Format(tree.AST()))
+
+	_, err = ParseAST(`$a >`)
+	require.NotNil(t, err)
+
+	program, err := Compile(input)
+	require.Nil(t, err)
+	require.Equal(t, `$a.b > 3 and startsWith($c, 'x')`, ast.Format(program.AST()))
+}
@@ -0,0 +1,229 @@
+package expr
+
+import (
+	"context"
+	"strings"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+// Trace records an evaluation node by node, see EvaluateWithTrace
+type Trace struct {
+	Root *TraceNode
+}
+
+// TraceNode is the evaluation of a node of the expression
+type TraceNode struct {
+	// Source is the text of the node, formatted if the program has no source, like the residual of PartialEval
+	Source string
+	// Start and End are the byte offsets of the node in the source expression
+	Start, End int
+	// Value is the computed value, nil if the node is skipped
+	Value interface{}
+	// Skipped is true if the node isn't evaluated, like `$b` in `false and $b`,
+	// nodes which don't finish because of a failure are skipped too
+	Skipped bool
+	// Err is the error of the node which fails the evaluation
+	Err      error
+	Children []*TraceNode
+	n        node
+	val      value
+}
+
+// EvaluateWithTrace is like Evaluate, and records every node evaluated,
+// the trace is returned with errors of evaluation, nodes after the failure are skipped
+func EvaluateWithTrace(expr string, vars map[string]interface{}) (bool, *Trace, error) {
+	return defaultEnv.EvaluateWithTrace(expr, vars)
+}
+
+// EvaluateWithTrace is like the package level EvaluateWithTrace, but expr is evaluated in e
+func (e *Env) EvaluateWithTrace(expr string, vars map[string]interface{}) (bool, *Trace, error) {
+	program, err := e.Compile(expr)
+	if err != nil {
+		return false, nil, err
+	}
+	trace, err := program.EvalWithTrace(vars)
+	if err != nil {
+		return false, trace, err
+	}
+	result, ok := trace.Root.Value.(bool)
+	if !ok {
+		return false, trace, zerror.BadRequest.Errorf(`expect bool result, got %s: %s`, trace.Root.val.kind, trace.Root.val)
+	}
+	return result, trace, nil
+}
+
+// EvalWithTrace evaluates the program like EvalValue, and records every node evaluated.
+// the expression is evaluated as written, without the optimizations of Compile, so folded nodes are traced too
+func (p *Program) EvalWithTrace(vars map[string]interface{}) (*Trace, error) {
+	root, source := p.root, ``
+	if p.tree != nil {
+		root, source = p.tree.root, p.tree.input
+	}
+	code, err := compileTrace(root, p.getFunc)
+	if err != nil {
+		return nil, err
+	}
+	m := p.newVM(context.Background(), vars, nil)
+	defer m.release()
+	m.trace = make([]value, len(code.traced))
+	m.reached = make([]bool, len(code.traced))
+	_, err = m.run(code)
+	b := &traceBuilder{source: source, indexes: make(map[node]int, len(code.traced)), m: m, failed: -1, err: err}
+	for i, n := range code.traced {
+		b.indexes[n] = i
+	}
+	if err != nil {
+		// the code of a node ends with its opTrace, so the first one after the failing instruction is of the innermost node
+		for _, in := range code.instrs[m.failed:] {
+			if in.op == opTrace {
+				b.failed = int(in.a)
+				break
+			}
+		}
+	}
+	return &Trace{Root: b.build(root)}, err
+}
+
+type traceBuilder struct {
+	source  string
+	indexes map[node]int
+	m       *vm
+	// the index of the traced node which fails, -1 if none
+	failed int
+	err    error
+}
+
+func (b *traceBuilder) build(n node) *TraceNode {
+	sp := n.pos()
+	t := &TraceNode{Start: sp.start, End: sp.end, Skipped: true, n: n}
+	if b.source != `` {
+		t.Source = b.source[sp.start:sp.end]
+	} else {
+		t.Source = formatNode(n)
+	}
+	if i, ok := b.indexes[n]; ok {
+		switch {
+		case b.m.reached[i]:
+			t.Skipped = false
+			t.val = b.m.trace[i]
+			t.Value = t.val.interfaceValue()
+		case i == b.failed:
+			t.Skipped = false
+			t.Err = b.err
+		}
+	}
+	for _, child := range children(n) {
+		t.Children = append(t.Children, b.build(child))
+	}
+	return t
+}
+
+// children returns the operands of n in the order of evaluation
+func children(n node) []node {
+	switch n := n.(type) {
+	case *unaryNode:
+		return []node{n.x}
+	case *binaryNode:
+		return []node{n.x, n.y}
+	case *inNode:
+		return []node{n.x, n.y}
+	case *listNode:
+		return n.elems
+	case *mapNode:
+		return n.values
+	case *callNode:
+		return n.args
+	}
+	return nil
+}
+
+// result formats the value of t, like `true`, `'byd'`, `skipped` or the error
+func (t *TraceNode) result() string {
+	if t.Skipped {
+		return `skipped`
+	}
+	if t.Err != nil {
+		return `error: ` + errorMessage(t.Err)
+	}
+	return t.val.literal()
+}
+
+// String renders the trace as an annotated tree, a node per line
+func (t *Trace) String() string {
+	var b strings.Builder
+	var render func(n *TraceNode, prefix, childPrefix string)
+	render = func(n *TraceNode, prefix, childPrefix string) {
+		b.WriteString(prefix + n.Source + ` => ` + n.result() + "\n")
+		for i, child := range n.Children {
+			if i == len(n.Children)-1 {
+				render(child, childPrefix+`└── `, childPrefix+`    `)
+			} else {
+				render(child, childPrefix+`├── `, childPrefix+`│   `)
+			}
+		}
+	}
+	render(t.Root, ``, ``)
+	return b.String()
+}
+
+// Explain lists the conditions which decide the bool result, like the failing clauses of a false `and`,
+// with the values of their operands which aren't literals. if the evaluation fails, it's the failing node
+func (t *Trace) Explain() string {
+	var b strings.Builder
+	nodes := deciding(t.Root)
+	if failed := findFailed(t.Root); failed != nil {
+		nodes = []*TraceNode{failed}
+	}
+	for _, n := range nodes {
+		b.WriteString(n.Source + ` is ` + n.result() + "\n")
+		for _, child := range n.Children {
+			if _, ok := child.n.(*literalNode); ok || child.Skipped {
+				continue
+			}
+			if _, ok := constValue(child.n); ok {
+				continue
+			}
+			b.WriteString(`    ` + child.Source + ` is ` + child.result() + "\n")
+		}
+	}
+	return b.String()
+}
+
+func findFailed(t *TraceNode) *TraceNode {
+	if t.Err != nil {
+		return t
+	}
+	for _, child := range t.Children {
+		if failed := findFailed(child); failed != nil {
+			return failed
+		}
+	}
+	return nil
+}
+
+// deciding returns the conditions under and, or and ! which decide the value of t
+func deciding(t *TraceNode) []*TraceNode {
+	if t.Skipped || t.Err != nil || t.val.kind != kindBool {
+		return []*TraceNode{t}
+	}
+	switch n := t.n.(type) {
+	case *unaryNode:
+		if n.op == operatorNot {
+			return deciding(t.Children[0])
+		}
+	case *binaryNode:
+		if n.op != operatorAnd && n.op != operatorOr {
+			break
+		}
+		// a false `and` is decided by its false operands, a true `or` by its true ones, otherwise operands are all equal to it
+		var nodes []*TraceNode
+		for _, child := range t.Children {
+			if !child.Skipped && child.val.b == t.val.b {
+				nodes = append(nodes, deciding(child)...)
+			}
+		}
+		return nodes
+	}
+	return []*TraceNode{t}
+}
@@ -0,0 +1,93 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateWithTrace(t *testing.T) {
+	vars := map[string]interface{}{`car`: `audi`, `a`: 1, `b`: `x`}
+	result, trace, err := EvaluateWithTrace(`$car in ('bwm','byd') and (3 + 2) * 2.0 = 10 and startsWith($car, 'b')`, vars)
+	require.Nil(t, err)
+	require.False(t, result)
+	require.Equal(t, `$car in ('bwm','byd') and (3 + 2) * 2.0 = 10 and startsWith($car, 'b') => false
+├── $car in ('bwm','byd') and (3 + 2) * 2.0 = 10 => false
+│   ├── $car in ('bwm','byd') => false
+│   │   ├── $car => 'audi'
+│   │   └── ('bwm','byd') => ['bwm', 'byd']
+│   │       ├── 'bwm' => 'bwm'
+│   │       └── 'byd' => 'byd'
+│   └── (3 + 2) * 2.0 = 10 => skipped
+│       ├── (3 + 2) * 2.0 => skipped
+│       │   ├── 3 + 2 => skipped
+│       │   │   ├── 3 => skipped
+│       │   │   └── 2 => skipped
+│       │   └── 2.0 => skipped
+│       └── 10 => skipped
+└── startsWith($car, 'b') => skipped
+    ├── $car => skipped
+    └── 'b' => skipped
+`, trace.String())
+	require.Equal(t, "$car in ('bwm','byd') is false\n    $car is 'audi'\n", trace.Explain())
+	cond := trace.Root.Children[0].Children[0]
+	require.Equal(t, 0, cond.Start)
+	require.Equal(t, 21, cond.End)
+	require.Equal(t, false, cond.Value)
+	require.Equal(t, `audi`, cond.Children[0].Value)
+
+	// nodes folded by Compile are traced
+	result, trace, err = EvaluateWithTrace(`$a > 1 or !($b = 'x') or length([1, $a]) = 2 or (3 + 2) * 2.0 = 10`, vars)
+	require.Nil(t, err)
+	require.True(t, result)
+	require.Equal(t, "length([1, $a]) = 2 is true\n    length([1, $a]) is 2\n", trace.Explain())
+	require.Equal(t, []interface{}{int64(1), int64(1)}, trace.Root.Children[0].Children[1].Children[0].Children[0].Value)
+	require.True(t, trace.Root.Children[1].Skipped)
+
+	result, trace, err = EvaluateWithTrace(`$b = 'x' and $missing = 1`, vars)
+	require.NotNil(t, err)
+	require.False(t, result)
+	missing := trace.Root.Children[1].Children[0]
+	require.Equal(t, err, missing.Err)
+	require.True(t, trace.Root.Skipped)
+	require.Equal(t, "$missing is error: var: missing not found\n", trace.Explain())
+}
+
+func TestTraceWithChangedFuncs(t *testing.T) {
+	env := NewEnv()
+	require.Nil(t, env.RegisterFunc(`f`, func() bool { return true }))
+	require.Nil(t, env.RegisterFunc(`g`, func(i int64) int64 { return i }, Pure()))
+	program, err := env.Compile(`f() and g(1) = 1`)
+	require.Nil(t, err)
+	// the trace explains the evaluation with the functions the program was compiled with
+	changes := []func(){
+		func() {
+			require.Nil(t, env.OverrideFunc(`f`, func() bool { return false }))
+			require.Nil(t, env.OverrideFunc(`g`, func(i int64) int64 { return 0 }, Pure()))
+		},
+		func() {
+			require.Nil(t, env.UnregisterFunc(`f`))
+			require.Nil(t, env.UnregisterFunc(`g`))
+		},
+	}
+	for _, change := range changes {
+		change()
+		result, err := program.Eval(nil)
+		require.Nil(t, err)
+		require.True(t, result)
+		trace, err := program.EvalWithTrace(nil)
+		require.Nil(t, err)
+		require.Equal(t, true, trace.Root.Value)
+		require.Equal(t, int64(1), trace.Root.Children[1].Children[0].Value)
+	}
+}
+
+func TestPartialEvalWithTrace(t *testing.T) {
+	program, err := Compile(`$a > 1 and $b = 'x'`)
+	require.Nil(t, err)
+	residual, err := PartialEval(program, map[string]interface{}{`a`: 2})
+	require.Nil(t, err)
+	trace, err := residual.EvalWithTrace(map[string]interface{}{`b`: `y`})
+	require.Nil(t, err)
+	require.Equal(t, "$b = 'x' => false\n├── $b => 'y'\n└── 'x' => 'x'\n", trace.String())
+}
@@ -0,0 +1,273 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+type kind uint8
+
+const (
+	// kindAny means the kind is only known at runtime
+	kindAny kind = iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	// slices and arrays
+	kindList
+	// maps with string keys
+	kindMap
+)
+
+var kindNames = [...]string{
+	kindAny:    `any`,
+	kindBool:   `bool`,
+	kindInt:    `int64`,
+	kindFloat:  `float64`,
+	kindString: `string`,
+	kindList:   `list`,
+	kindMap:    `map`,
+}
+
+func (k kind) String() string {
+	return kindNames[k]
+}
+
+func (k kind) isNumber() bool {
+	return k == kindInt || k == kindFloat
+}
+
+var kindTypes = [...]reflect.Type{
+	kindBool:   reflect.TypeOf(false),
+	kindInt:    reflect.TypeOf(int64(0)),
+	kindFloat:  reflect.TypeOf(float64(0)),
+	kindString: reflect.TypeOf(``),
+	kindList:   reflect.TypeOf([]interface{}{}),
+	kindMap:    reflect.TypeOf(map[string]interface{}{}),
+}
+
+// reflectType returns nil for kindAny
+func (k kind) reflectType() reflect.Type {
+	return kindTypes[k]
+}
+
+// kindOfType returns kindInt for all ints and uints, and kindFloat for all floats, they are converted when used
+func kindOfType(t reflect.Type) kind {
+	switch k := t.Kind(); k {
+	case reflect.Bool:
+		return kindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return kindInt
+	case reflect.Float32, reflect.Float64:
+		return kindFloat
+	case reflect.String:
+		return kindString
+	case reflect.Slice, reflect.Array:
+		return kindList
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return kindMap
+		}
+	}
+	return kindAny
+}
+
+// value is a register of the vm, only the field of kind is meaningful
+type value struct {
+	kind kind
+	b    bool
+	i    int64
+	f    float64
+	s    string
+	// the slice, array or map of kindList and kindMap, elements are converted when used,
+	// or the raw variable of kindAny
+	x interface{}
+}
+
+func boolValue(b bool) value        { return value{kind: kindBool, b: b} }
+func intValue(i int64) value        { return value{kind: kindInt, i: i} }
+func floatValue(f float64) value    { return value{kind: kindFloat, f: f} }
+func stringValue(s string) value    { return value{kind: kindString, s: s} }
+func listValue(x interface{}) value { return value{kind: kindList, x: x} }
+func mapValue(x interface{}) value  { return value{kind: kindMap, x: x} }
+
+func (v value) float() float64 {
+	if v.kind == kindInt {
+		return float64(v.i)
+	}
+	return v.f
+}
+
+func (v value) interfaceValue() interface{} {
+	switch v.kind {
+	case kindBool:
+		return v.b
+	case kindInt:
+		return v.i
+	case kindFloat:
+		return v.f
+	case kindString:
+		return v.s
+	}
+	// x of kindAny is the raw variable passed to interface{} parameters
+	return v.x
+}
+
+func (v value) String() string {
+	switch v.kind {
+	case kindBool:
+		return strconv.FormatBool(v.b)
+	case kindInt:
+		return strconv.FormatInt(v.i, 10)
+	case kindFloat:
+		return strconv.FormatFloat(v.f, 'g', -1, 64)
+	case kindString:
+		return v.s
+	case kindList, kindMap:
+		return fmt.Sprint(v.x)
+	}
+	return `<nil>`
+}
+
+func (v value) reflectValue() reflect.Value {
+	return reflect.ValueOf(v.interfaceValue())
+}
+
+// valueOf converts a normalized variable into value
+func valueOf(name string, i interface{}) (value, error) {
+	switch x := i.(type) {
+	case bool:
+		return boolValue(x), nil
+	case int64:
+		return intValue(x), nil
+	case float64:
+		return floatValue(x), nil
+	case string:
+		return stringValue(x), nil
+	case []interface{}:
+		return listValue(x), nil
+	case map[string]interface{}:
+		return mapValue(x), nil
+	}
+	nv, err := normalizeVariable(name, i)
+	if err != nil {
+		return value{}, err
+	}
+	switch nv.(type) {
+	case bool, int64, float64, string:
+		return valueOf(name, nv)
+	}
+	switch kindOfType(reflect.TypeOf(nv)) {
+	case kindList:
+		return listValue(nv), nil
+	case kindMap:
+		return mapValue(nv), nil
+	}
+	return value{}, zerror.BadRequest.Errorf(`variable name: %s, type: %T can not be used as a value`, name, i)
+}
+
+// valueOfReflect converts results of functions into value, uints greater than math.MaxInt64 overflow
+func valueOfReflect(rv reflect.Value) (value, error) {
+	switch k := rv.Kind(); {
+	case k == reflect.Bool:
+		return boolValue(rv.Bool()), nil
+	case isIntKind(k):
+		return intValue(rv.Int()), nil
+	case isUintKind(k):
+		if rv.Uint() > math.MaxInt64 {
+			return value{}, errOverflow(rv.Interface(), kindInt.reflectType())
+		}
+		return intValue(int64(rv.Uint())), nil
+	case isFloatKind(k):
+		return floatValue(rv.Float()), nil
+	case k == reflect.String:
+		return stringValue(rv.String()), nil
+	case k == reflect.Interface:
+		if rv.IsNil() {
+			return value{}, zerror.BadRequest.WithMsg(`nil can not be used as a value`)
+		}
+		return valueOf(`result`, rv.Interface())
+	}
+	switch kindOfType(rv.Type()) {
+	case kindList:
+		return listValue(rv.Interface()), nil
+	case kindMap:
+		return mapValue(rv.Interface()), nil
+	}
+	return value{}, zerror.BadRequest.Errorf(`type: %s not supported`, rv.Type())
+}
+
+// equal compares numbers by their float values, values of different kinds are not equal
+func (v value) equal(o value) bool {
+	if v.kind == o.kind {
+		switch v.kind {
+		case kindBool:
+			return v.b == o.b
+		case kindInt:
+			return v.i == o.i
+		case kindFloat:
+			return v.f == o.f
+		case kindString:
+			return v.s == o.s
+		}
+		return false
+	}
+	if v.kind.isNumber() && o.kind.isNumber() {
+		return v.float() == o.float()
+	}
+	return false
+}
+
+// contains reports if list v has an element equal to x, or map v has the key x
+func (v value) contains(x value) (bool, error) {
+	if x.kind == kindBool {
+		return false, zerror.BadRequest.Errorf(`invalid in operand: (%s)%s`, x.kind, x)
+	}
+	switch v.kind {
+	case kindList:
+		switch list := v.x.(type) {
+		case []string:
+			for _, e := range list {
+				if x.kind == kindString && x.s == e {
+					return true, nil
+				}
+			}
+			return false, nil
+		case []int64:
+			for _, e := range list {
+				if x.equal(intValue(e)) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		rv := reflect.ValueOf(v.x)
+		for i := 0; i < rv.Len(); i++ {
+			e, err := valueOf(`element`, rv.Index(i).Interface())
+			if err != nil {
+				return false, err
+			}
+			if x.equal(e) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case kindMap:
+		if x.kind != kindString {
+			return false, zerror.BadRequest.Errorf(`map keys are strings, got (%s)%s`, x.kind, x)
+		}
+		if m, ok := v.x.(map[string]interface{}); ok {
+			_, ok := m[x.s]
+			return ok, nil
+		}
+		rv := reflect.ValueOf(v.x)
+		return rv.MapIndex(reflect.ValueOf(x.s).Convert(rv.Type().Key())).IsValid(), nil
+	}
+	return false, zerror.BadRequest.Errorf(`can not use in with (%s)%s`, v.kind, v)
+}
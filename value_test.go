@@ -0,0 +1,99 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollections(t *testing.T) {
+	vars := map[string]interface{}{
+		`roles`:  []string{`admin`, `dev`},
+		`ids`:    []int{1, 2, 3},
+		`scores`: [2]float32{1.5, 2},
+		`attrs`:  map[string]interface{}{`vip`: true, `level`: 3},
+		`labels`: map[string]string{`env`: `prod`},
+		`empty`:  []interface{}{},
+		`user`:   map[string]interface{}{`groups`: []interface{}{`a`, 1}},
+		`name`:   `admin`,
+	}
+	trues := []string{
+		`'admin' in $roles`,
+		`'ops' not in $roles`,
+		`$name in $roles`,
+		`2 in $ids`,
+		`2.0 in $ids`,
+		`1.5 in $scores`,
+		`'vip' in $attrs`,
+		`'env' in $labels and 'prod' not in $labels`,
+		`1 in $user.groups and 'a' in $user.groups`,
+		`3 in [1, 2, 3]`,
+		`$name in ['ops', $name]`,
+		`'a' in {'a': 1, 'b': [2, 3]}`,
+		`'c' not in {'a': 1}`,
+		`4 not in []`,
+		`length($roles) = 2`,
+		`length($attrs) = 2`,
+		`length($scores) = 2`,
+		`length($empty) = 0`,
+		`length($name) = 5`,
+	}
+	for _, input := range trues {
+		testEvaluator(t, input, vars, true)
+	}
+	invalids := []string{
+		`1 in $attrs`,
+		`'a' in $name`,
+		`true in $roles`,
+		`$attrs > 1`,
+		`$roles = 1`,
+		`-$roles > 1`,
+		`$roles + 1 > 1`,
+		`length($attrs.level) = 1`,
+	}
+	for _, input := range invalids {
+		_, err := Evaluate(input, vars)
+		require.NotNil(t, err, input)
+	}
+}
+
+func TestCollectionLiterals(t *testing.T) {
+	cases := map[string]interface{}{
+		`[1, 'a', true]`:             []interface{}{int64(1), `a`, true},
+		`[]`:                         []interface{}{},
+		`[$a, $a + 1, $a > 1]`:       []interface{}{int64(1), int64(2), false},
+		`{'a': 1, 'b': [2.5]}`:       map[string]interface{}{`a`: int64(1), `b`: []interface{}{2.5}},
+		`{'a': $a, 'b': 1 > 0}`:      map[string]interface{}{`a`: int64(1), `b`: true},
+		`{}`:                         map[string]interface{}{},
+		`$list`:                      []string{`x`},
+		`{'nested': {'\'q': $list}}`: map[string]interface{}{`nested`: map[string]interface{}{`'q`: []string{`x`}}},
+	}
+	vars := map[string]interface{}{`a`: 1, `list`: []string{`x`}}
+	for input, expect := range cases {
+		result, err := EvaluateValue(input, vars)
+		require.Nil(t, err, input)
+		require.Equal(t, expect, result, input)
+	}
+
+	invalids := []string{
+		`{'a': 1, 'a': 2}`,
+		`[1] > 1`,
+		`{'a': 1} = 1`,
+		`-[1]`,
+		`[1] + 1`,
+		`1 in 'a'`,
+		`1 in {'a': 1}`,
+		`[1] in [[1]]`,
+		`[1,]`,
+		`{'a'}`,
+	}
+	for _, input := range invalids {
+		_, err := NewParser().ParseValueWithCache(input)
+		require.NotNil(t, err, input)
+	}
+
+	program, err := CompileValue(`[1, 2]`)
+	require.Nil(t, err)
+	require.Equal(t, opConst, program.code.instrs[0].op)
+	require.Equal(t, kindList, program.kind)
+}
@@ -0,0 +1,635 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/EchoUtopia/zerror"
+)
+
+type opcode uint8
+
+const (
+	// dst = consts[a]
+	opConst opcode = iota
+	// dst = vars[names[a]], mode 1 requires bool
+	opVar
+	// dst = paths[b] in vars[names[a]], mode 1 requires bool
+	opVarPath
+	// fails with names[a]
+	opIdent
+	opNot
+	// jumps to b if register a is false
+	opJumpFalse
+	// jumps to b if register a is true
+	opJumpTrue
+	opNeg
+	opNegInt
+	opNegFloat
+	opToFloat
+	// dst = a (operator mode) b, kinds are checked at runtime
+	opMath
+	opAddInt
+	opSubInt
+	opMulInt
+	opDivInt
+	opModInt
+	opPowInt
+	opAddFloat
+	opSubFloat
+	opMulFloat
+	opDivFloat
+	opModFloat
+	opPowFloat
+	// dst = a (operator mode) b
+	opCmp
+	opCmpInt
+	opCmpFloat
+	opCmpString
+	// dst = a in lists[b], mode 1 for not in
+	opIn
+	// dst = a in b, b is a list or a map, mode 1 for not in
+	opInCollection
+	// dst = list of registers a...a+b
+	opList
+	// dst = map of keys[b] to registers a...
+	opMap
+	// dst = funcs[a](registers b...b+mode)
+	opCall
+	// dst = slots[a] and jumps to b if the slot is set
+	opLoadSlot
+	// slots[a] = dst
+	opStoreSlot
+	// records dst as the value of traced[a]
+	opTrace
+)
+
+var opcodeNames = [...]string{
+	opConst:        `const`,
+	opVar:          `var`,
+	opVarPath:      `var.path`,
+	opIdent:        `ident`,
+	opNot:          `not`,
+	opJumpFalse:    `jump.false`,
+	opJumpTrue:     `jump.true`,
+	opNeg:          `neg`,
+	opNegInt:       `neg.int`,
+	opNegFloat:     `neg.float`,
+	opToFloat:      `tofloat`,
+	opMath:         `math`,
+	opAddInt:       `add.int`,
+	opSubInt:       `sub.int`,
+	opMulInt:       `mul.int`,
+	opDivInt:       `div.int`,
+	opModInt:       `mod.int`,
+	opPowInt:       `pow.int`,
+	opAddFloat:     `add.float`,
+	opSubFloat:     `sub.float`,
+	opMulFloat:     `mul.float`,
+	opDivFloat:     `div.float`,
+	opModFloat:     `mod.float`,
+	opPowFloat:     `pow.float`,
+	opCmp:          `cmp`,
+	opCmpInt:       `cmp.int`,
+	opCmpFloat:     `cmp.float`,
+	opCmpString:    `cmp.string`,
+	opIn:           `in`,
+	opInCollection: `in.collection`,
+	opList:         `list`,
+	opMap:          `map`,
+	opCall:         `call`,
+	opLoadSlot:     `slot.load`,
+	opStoreSlot:    `slot.store`,
+	opTrace:        `trace`,
+}
+
+func (op opcode) String() string {
+	return opcodeNames[op]
+}
+
+type instr struct {
+	op   opcode
+	mode uint8
+	dst  int32
+	a, b int32
+}
+
+// bytecode is immutable once generated
+type bytecode struct {
+	instrs []instr
+	consts []value
+	names  []string
+	funcs  []*function
+	lists  [][]value
+	paths  [][]pathStep
+	keys   [][]string
+	nregs  int
+	// the number of shared sub-expressions
+	nslots int
+	// nodes recorded by opTrace, see compileTrace
+	traced []node
+}
+
+// String disassembles the bytecode, one instruction per line
+func (c *bytecode) String() string {
+	var b strings.Builder
+	for pc, in := range c.instrs {
+		fmt.Fprintf(&b, "%04d %-13s %s\n", pc, in.op, c.operands(in))
+	}
+	return b.String()
+}
+
+func (c *bytecode) operands(in instr) string {
+	r := func(reg int32) string { return `r` + strconv.Itoa(int(reg)) }
+	switch in.op {
+	case opConst:
+		return fmt.Sprintf(`%s = %s`, r(in.dst), c.consts[in.a].literal())
+	case opVar, opVarPath:
+		var path []pathStep
+		if in.op == opVarPath {
+			path = c.paths[in.b]
+		}
+		s := fmt.Sprintf(`%s = %s`, r(in.dst), pathString(c.names[in.a], path))
+		switch in.mode {
+		case varBool:
+			s += ` (bool)`
+		case varRaw:
+			s += ` (raw)`
+		}
+		return s
+	case opIdent:
+		return c.names[in.a]
+	case opJumpFalse, opJumpTrue:
+		return fmt.Sprintf(`%s -> %04d`, r(in.a), in.b)
+	case opNot, opNeg, opNegInt, opNegFloat, opToFloat:
+		return fmt.Sprintf(`%s = %s`, r(in.dst), r(in.a))
+	case opMath, opCmp, opCmpInt, opCmpFloat, opCmpString:
+		return fmt.Sprintf(`%s = %s %s %s`, r(in.dst), r(in.a), operator(in.mode), r(in.b))
+	case opIn:
+		elems := make([]string, len(c.lists[in.b]))
+		for i, v := range c.lists[in.b] {
+			elems[i] = v.literal()
+		}
+		return fmt.Sprintf(`%s = %s %s [%s]`, r(in.dst), r(in.a), inText(in.mode), strings.Join(elems, `, `))
+	case opInCollection:
+		return fmt.Sprintf(`%s = %s %s %s`, r(in.dst), r(in.a), inText(in.mode), r(in.b))
+	case opList:
+		return fmt.Sprintf(`%s = [%s...r%d]`, r(in.dst), r(in.a), in.a+in.b-1)
+	case opMap:
+		return fmt.Sprintf(`%s = {%s from %s}`, r(in.dst), strings.Join(c.keys[in.b], `, `), r(in.a))
+	case opCall:
+		args := make([]string, in.mode)
+		for i := range args {
+			args[i] = r(in.b + int32(i))
+		}
+		return fmt.Sprintf(`%s = %s(%s)`, r(in.dst), c.funcs[in.a].name, strings.Join(args, `, `))
+	case opLoadSlot:
+		return fmt.Sprintf(`%s = slot%d -> %04d`, r(in.dst), in.a, in.b)
+	case opStoreSlot:
+		return fmt.Sprintf(`slot%d = %s`, in.a, r(in.dst))
+	case opTrace:
+		return fmt.Sprintf(`%s: %s`, formatNode(c.traced[in.a]), r(in.dst))
+	}
+	// math of ints and floats
+	return fmt.Sprintf(`%s = %s, %s`, r(in.dst), r(in.a), r(in.b))
+}
+
+func inText(not uint8) string {
+	if not == 1 {
+		return `not in`
+	}
+	return `in`
+}
+
+type vm struct {
+	regs []value
+	// results of shared sub-expressions, unset slots are of kindAny
+	slots []value
+	vars  map[string]interface{}
+	// variables bound by PartialEval
+	bound map[string]interface{}
+	ctx   context.Context
+	// values of traced nodes, and whether they are evaluated
+	trace   []value
+	reached []bool
+	// the instruction which fails the evaluation
+	failed int
+	// cost of the evaluation so far, see WithCostBudget
+	cost int64
+	evalOptions
+}
+
+func (m *vm) reset() {
+	for i := range m.regs {
+		m.regs[i] = value{}
+	}
+	for i := range m.slots {
+		m.slots[i] = value{}
+	}
+	m.vars = nil
+	m.bound = nil
+	m.ctx = nil
+	m.trace = nil
+	m.reached = nil
+	m.failed = 0
+	m.cost = 0
+	m.evalOptions = evalOptions{}
+}
+
+// nodeCost is the cost of instructions which evaluate a node
+func nodeCost(op opcode) int64 {
+	switch op {
+	case opJumpFalse, opJumpTrue, opToFloat, opLoadSlot, opStoreSlot, opTrace:
+		return 0
+	}
+	return 1
+}
+
+// run executes code, the result is left in register 0
+func (m *vm) run(code *bytecode) (value, error) {
+	m.cost = 0
+	m.clearSlots(code.nslots)
+	return m.exec(code)
+}
+
+// clearSlots unsets the results of n shared sub-expressions
+func (m *vm) clearSlots(n int) {
+	if n == 0 {
+		return
+	}
+	if cap(m.slots) < n {
+		m.slots = make([]value, n)
+	}
+	m.slots = m.slots[:n]
+	for i := range m.slots {
+		m.slots[i] = value{}
+	}
+}
+
+// exec is like run, but slots are kept, so codes compiled together by compileNodes share results when run one after another,
+// and costs of them add up
+func (m *vm) exec(code *bytecode) (result value, err error) {
+	pc := 0
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = zerror.Internal.Errorf(`panic: %v`, recovered)
+		}
+		if err != nil {
+			m.failed = pc - 1
+		}
+	}()
+	if cap(m.regs) < code.nregs {
+		m.regs = make([]value, code.nregs)
+	}
+	regs := m.regs[:code.nregs]
+	done := m.ctx.Done()
+	for pc < len(code.instrs) {
+		in := &code.instrs[pc]
+		pc++
+		if done != nil {
+			select {
+			case <-done:
+				return value{}, Canceled.Wrap(m.ctx.Err())
+			default:
+			}
+		}
+		if m.budget > 0 {
+			m.cost += nodeCost(in.op)
+			if in.op == opCall {
+				m.cost += code.funcs[in.a].cost
+			}
+			if m.cost > m.budget {
+				return value{}, BudgetExceeded.New().WithData(zerror.Data{`budget`: m.budget, `cost`: m.cost})
+			}
+		}
+		switch in.op {
+		case opConst:
+			regs[in.dst] = code.consts[in.a]
+		case opVar:
+			v, err := m.loadVar(code.names[in.a], nil, in.mode)
+			if err != nil {
+				return value{}, err
+			}
+			regs[in.dst] = v
+		case opVarPath:
+			v, err := m.loadVar(code.names[in.a], code.paths[in.b], in.mode)
+			if err != nil {
+				return value{}, err
+			}
+			regs[in.dst] = v
+		case opIdent:
+			return value{}, zerror.BadRequest.Errorf(`can not evaluate expression consists of identifier`)
+		case opNot:
+			x := regs[in.a]
+			if x.kind != kindBool {
+				return value{}, zerror.BadRequest.Errorf(`can not negate (%s)%s`, x.kind, x)
+			}
+			regs[in.dst] = boolValue(!x.b)
+		case opJumpFalse:
+			if !regs[in.a].b {
+				pc = int(in.b)
+			}
+		case opJumpTrue:
+			if regs[in.a].b {
+				pc = int(in.b)
+			}
+		case opNeg:
+			x := regs[in.a]
+			switch x.kind {
+			case kindInt:
+				regs[in.dst] = intValue(-x.i)
+			case kindFloat:
+				regs[in.dst] = floatValue(-x.f)
+			default:
+				return value{}, zerror.BadRequest.Errorf(`can not do math operation with type: %s, value: %s`, x.kind, x)
+			}
+		case opNegInt:
+			regs[in.dst] = intValue(-regs[in.a].i)
+		case opNegFloat:
+			regs[in.dst] = floatValue(-regs[in.a].f)
+		case opToFloat:
+			regs[in.dst] = floatValue(float64(regs[in.a].i))
+		case opMath:
+			v, err := arith(operator(in.mode), regs[in.a], regs[in.b])
+			if err != nil {
+				return value{}, err
+			}
+			regs[in.dst] = v
+		case opAddInt:
+			regs[in.dst] = intValue(regs[in.a].i + regs[in.b].i)
+		case opSubInt:
+			regs[in.dst] = intValue(regs[in.a].i - regs[in.b].i)
+		case opMulInt:
+			regs[in.dst] = intValue(regs[in.a].i * regs[in.b].i)
+		case opDivInt:
+			if regs[in.b].i == 0 {
+				return value{}, errDivideByZero
+			}
+			regs[in.dst] = intValue(regs[in.a].i / regs[in.b].i)
+		case opModInt:
+			if regs[in.b].i == 0 {
+				return value{}, errDivideByZero
+			}
+			regs[in.dst] = intValue(regs[in.a].i % regs[in.b].i)
+		case opPowInt:
+			v, err := powInt(regs[in.a].i, regs[in.b].i)
+			if err != nil {
+				return value{}, err
+			}
+			regs[in.dst] = intValue(v)
+		case opAddFloat:
+			regs[in.dst] = floatValue(regs[in.a].f + regs[in.b].f)
+		case opSubFloat:
+			regs[in.dst] = floatValue(regs[in.a].f - regs[in.b].f)
+		case opMulFloat:
+			regs[in.dst] = floatValue(regs[in.a].f * regs[in.b].f)
+		case opDivFloat:
+			regs[in.dst] = floatValue(regs[in.a].f / regs[in.b].f)
+		case opModFloat:
+			regs[in.dst] = floatValue(math.Mod(regs[in.a].f, regs[in.b].f))
+		case opPowFloat:
+			regs[in.dst] = floatValue(math.Pow(regs[in.a].f, regs[in.b].f))
+		case opCmp:
+			v, err := compare(operator(in.mode), regs[in.a], regs[in.b])
+			if err != nil {
+				return value{}, err
+			}
+			regs[in.dst] = v
+		case opCmpInt:
+			x, y := regs[in.a].i, regs[in.b].i
+			regs[in.dst] = boolValue(compareResult(operator(in.mode), x == y, x < y, x > y))
+		case opCmpFloat:
+			x, y := regs[in.a].f, regs[in.b].f
+			regs[in.dst] = boolValue(compareResult(operator(in.mode), x == y, x < y, x > y))
+		case opCmpString:
+			x, y := regs[in.a].s, regs[in.b].s
+			regs[in.dst] = boolValue(compareResult(operator(in.mode), x == y, x < y, x > y))
+		case opIn:
+			v, err := inList(regs[in.a], code.lists[in.b])
+			if err != nil {
+				return value{}, err
+			}
+			regs[in.dst] = boolValue(v != (in.mode == 1))
+		case opInCollection:
+			v, err := regs[in.b].contains(regs[in.a])
+			if err != nil {
+				return value{}, err
+			}
+			regs[in.dst] = boolValue(v != (in.mode == 1))
+		case opList:
+			list := make([]interface{}, in.b)
+			for i := range list {
+				list[i] = regs[in.a+int32(i)].interfaceValue()
+			}
+			regs[in.dst] = listValue(list)
+		case opMap:
+			keys := code.keys[in.b]
+			m := make(map[string]interface{}, len(keys))
+			for i, key := range keys {
+				m[key] = regs[in.a+int32(i)].interfaceValue()
+			}
+			regs[in.dst] = mapValue(m)
+		case opCall:
+			fn := code.funcs[in.a]
+			v, err := fn.call(m.ctx, regs[in.b:in.b+int32(in.mode)])
+			if err != nil {
+				return value{}, err
+			}
+			regs[in.dst] = v
+		case opLoadSlot:
+			if v := m.slots[in.a]; v.kind != kindAny {
+				regs[in.dst] = v
+				pc = int(in.b)
+			}
+		case opStoreSlot:
+			m.slots[in.a] = regs[in.dst]
+		case opTrace:
+			m.trace[in.a] = regs[in.dst]
+			m.reached[in.a] = true
+		}
+	}
+	return regs[0], nil
+}
+
+// modes of opVar and opVarPath
+const (
+	varValue uint8 = iota
+	// the variable must be bool
+	varBool
+	// the variable is passed to interface{} parameters as is
+	varRaw
+)
+
+func (m *vm) loadVar(name string, path []pathStep, mode uint8) (value, error) {
+	i, ok := m.vars[name]
+	if !ok {
+		i, ok = m.bound[name]
+	}
+	if !ok {
+		names := make([]string, 0, len(m.vars)+len(m.bound))
+		for _, vars := range []map[string]interface{}{m.vars, m.bound} {
+			for name := range vars {
+				names = append(names, name)
+			}
+		}
+		return value{}, errNotFound(`var`, name, suggest(name, names))
+	}
+	if len(path) > 0 {
+		var err error
+		if i, err = walkPath(name, i, path); err != nil {
+			return value{}, err
+		}
+		name = pathString(name, path)[1:]
+	}
+	if mode == varRaw {
+		return value{x: i}, nil
+	}
+	v, err := valueOf(name, i)
+	if err != nil {
+		return value{}, err
+	}
+	if mode == varBool && v.kind != kindBool {
+		return value{}, zerror.BadRequest.Errorf(`$%s expect bool, got: %s`, name, v.kind)
+	}
+	return v, nil
+}
+
+var errDivideByZero = zerror.BadRequest.WithMsg(`integer divide by zero`)
+
+func arith(op operator, x, y value) (value, error) {
+	if !x.kind.isNumber() || !y.kind.isNumber() {
+		bad := x
+		if x.kind.isNumber() {
+			bad = y
+		}
+		return value{}, zerror.BadRequest.Errorf(`can not do math operation with type: %s, value: %s`, bad.kind, bad)
+	}
+	if x.kind == kindInt && y.kind == kindInt {
+		switch op {
+		case operatorAdd:
+			return intValue(x.i + y.i), nil
+		case operatorSub:
+			return intValue(x.i - y.i), nil
+		case operatorMul:
+			return intValue(x.i * y.i), nil
+		case operatorDiv:
+			if y.i == 0 {
+				return value{}, errDivideByZero
+			}
+			return intValue(x.i / y.i), nil
+		case operatorMod:
+			if y.i == 0 {
+				return value{}, errDivideByZero
+			}
+			return intValue(x.i % y.i), nil
+		case operatorPow:
+			v, err := powInt(x.i, y.i)
+			if err != nil {
+				return value{}, err
+			}
+			return intValue(v), nil
+		}
+	}
+	fx, fy := x.float(), y.float()
+	switch op {
+	case operatorAdd:
+		return floatValue(fx + fy), nil
+	case operatorSub:
+		return floatValue(fx - fy), nil
+	case operatorMul:
+		return floatValue(fx * fy), nil
+	case operatorDiv:
+		return floatValue(fx / fy), nil
+	case operatorMod:
+		return floatValue(math.Mod(fx, fy)), nil
+	case operatorPow:
+		return floatValue(math.Pow(fx, fy)), nil
+	}
+	return value{}, zerror.Internal.Errorf(`unknown math operator: %s`, op)
+}
+
+// powInt keeps the result int64, negative exponents and overflows fail instead of losing precision
+func powInt(x, y int64) (int64, error) {
+	if y < 0 {
+		return 0, zerror.BadRequest.Errorf(`negative exponent of integer power: %d ** %d, use a float instead`, x, y)
+	}
+	result := int64(1)
+	for base, e := x, y; e > 0; e >>= 1 {
+		var ok bool
+		if e&1 == 1 {
+			if result, ok = mulInt(result, base); !ok {
+				return 0, zerror.BadRequest.Errorf(`integer overflow: %d ** %d`, x, y)
+			}
+		}
+		if e > 1 {
+			if base, ok = mulInt(base, base); !ok {
+				return 0, zerror.BadRequest.Errorf(`integer overflow: %d ** %d`, x, y)
+			}
+		}
+	}
+	return result, nil
+}
+
+func mulInt(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || a == -1 && b == math.MinInt64 || b == -1 && a == math.MinInt64 {
+		return 0, false
+	}
+	return c, true
+}
+
+// compare works between strings or between numbers, bools can only be compared by = and !=
+func compare(op operator, x, y value) (value, error) {
+	var eq, lt, gt bool
+	switch {
+	case x.kind == kindString && y.kind == kindString:
+		c := strings.Compare(x.s, y.s)
+		eq, lt, gt = c == 0, c < 0, c > 0
+	case x.kind == kindInt && y.kind == kindInt:
+		eq, lt, gt = x.i == y.i, x.i < y.i, x.i > y.i
+	case x.kind.isNumber() && y.kind.isNumber():
+		fx, fy := x.float(), y.float()
+		eq, lt, gt = fx == fy, fx < fy, fx > fy
+	case x.kind == kindBool && y.kind == kindBool && (op == operatorEQ || op == operatorNEQ):
+		eq = x.b == y.b
+	default:
+		return value{}, zerror.BadRequest.Errorf(`(%s)%s and (%s)%s is not comparable`, x.kind, x, y.kind, y)
+	}
+	return boolValue(compareResult(op, eq, lt, gt)), nil
+}
+
+func compareResult(op operator, eq, lt, gt bool) bool {
+	switch op {
+	case operatorEQ:
+		return eq
+	case operatorNEQ:
+		return !eq
+	case operatorGT:
+		return gt
+	case operatorGTE:
+		return gt || eq
+	case operatorLT:
+		return lt
+	case operatorLTE:
+		return lt || eq
+	}
+	return false
+}
+
+func inList(x value, list []value) (bool, error) {
+	if x.kind == kindBool {
+		return false, zerror.BadRequest.Errorf(`invalid in operand: (%s)%s`, x.kind, x)
+	}
+	for _, v := range list {
+		if x.equal(v) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
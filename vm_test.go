@@ -0,0 +1,134 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVMRuntimeErrors(t *testing.T) {
+	vars := map[string]interface{}{
+		`int`:    1,
+		`zero`:   0,
+		`bool`:   true,
+		`string`: `str`,
+	}
+	invalids := []string{
+		`$int / $zero = 1`,
+		`1 / 0 = 1`,
+		`$string + 1 > 1`,
+		`-$string > 1`,
+		`$string > 1`,
+		`$string = 1`,
+		`$bool > $bool`,
+		`$bool in (1, 2)`,
+		`$int`,
+		`!$string`,
+		`$missing > 1`,
+		`a > 1`,
+	}
+	for _, v := range invalids {
+		_, err := Evaluate(v, vars)
+		require.NotNil(t, err, v)
+	}
+	trues := []string{
+		`$bool = $bool`,
+		`1.0 / 0 > 1`,
+		`$int / 2 = 0`,
+		`$string not in (1, 2)`,
+		`-$int * 1.5 = -1.5`,
+	}
+	for _, v := range trues {
+		testEvaluator(t, v, vars, true)
+	}
+}
+
+func TestCodegenSpecializes(t *testing.T) {
+	cases := map[string]opcode{
+		`t_int() + 2 > 1`:       opAddInt,
+		`t_int() + 2.0 > 1`:     opAddFloat,
+		`$a + 1 > 1`:            opMath,
+		`t_int() > 1`:           opCmpInt,
+		`t_float() > 1`:         opCmpFloat,
+		`t_string() > 'a'`:      opCmpString,
+		`$a > 1`:                opCmp,
+		`-t_float() > t_int()`:  opNegFloat,
+		`t_int() * t_int() > 1`: opMulInt,
+		`t_int() % 2 = 1`:       opModInt,
+		`t_float() % 2 = 1`:     opModFloat,
+		`t_int() ** 2 = 1`:      opPowInt,
+		`t_int() ** 0.5 > 1`:    opPowFloat,
+	}
+	for input, op := range cases {
+		program, err := Compile(input)
+		require.Nil(t, err, input)
+		found := false
+		for _, in := range program.code.instrs {
+			if in.op == op {
+				found = true
+			}
+		}
+		require.True(t, found, `%s: %s not emitted`, input, op)
+	}
+}
+
+func TestFunctionCallPaths(t *testing.T) {
+	type namedFunc func(string, string) bool
+	parser := NewParser()
+	// func(string, string) bool is called without reflect, namedFunc goes through reflect
+	require.Nil(t, parser.RegisterFunc(`fast`, func(a, b string) bool { return a == b }))
+	require.Nil(t, parser.RegisterFunc(`slow`, namedFunc(func(a, b string) bool { return a == b })))
+	for _, input := range []string{`fast('a', $s)`, `slow('a', $s)`} {
+		program, err := parser.Compile(input)
+		require.Nil(t, err, input)
+		result, err := program.Eval(map[string]interface{}{`s`: `a`})
+		require.Nil(t, err, input)
+		require.True(t, result, input)
+		_, err = program.Eval(map[string]interface{}{`s`: 1})
+		require.NotNil(t, err, input)
+	}
+}
+
+func TestModAndPow(t *testing.T) {
+	vars := map[string]interface{}{`id`: 1234, `price`: 2.5, `tier`: 3}
+	cases := map[string]interface{}{
+		`$id % 10`:          int64(4),
+		`-7 % 3`:            int64(-1),
+		`7.5 % 2`:           1.5,
+		`$price % 1`:        0.5,
+		`2 ** 10`:           int64(1024),
+		`2 ** 3 ** 2`:       int64(512),
+		`(2 ** 3) ** 2`:     int64(64),
+		`- 2 ** 2`:          int64(-4),
+		`2 * 3 ** 2 % 5`:    int64(3),
+		`2 ** -1.0`:         0.5,
+		`4 ** 0.5`:          2.0,
+		`$price ** 2`:       6.25,
+		`$tier ** 2 * 10`:   int64(90),
+		`(-2) ** 63`:        int64(-9223372036854775808),
+		`$id % 7 ** 2 + 1`:  int64(1234%49 + 1),
+		`10 - $id % 10 * 2`: int64(2),
+	}
+	for input, expect := range cases {
+		result, err := EvaluateValue(input, vars)
+		require.Nil(t, err, input)
+		require.Equal(t, expect, result, input)
+	}
+	testEvaluator(t, `$id % 10 < 5`, vars, true)
+
+	invalids := []string{
+		`$id % 0`,
+		`1 % 0`,
+		`2 ** -1`,
+		`$tier ** -1`,
+		`2 ** 63`,
+		`3 ** 40`,
+		`'a' % 2`,
+		`2 ** 'a'`,
+		`2 ***  2`,
+	}
+	for _, input := range invalids {
+		_, err := EvaluateValue(input, vars)
+		require.NotNil(t, err, input)
+	}
+}